@@ -0,0 +1,284 @@
+package diagnostics
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sarulabs/di"
+	"go.uber.org/zap"
+)
+
+const maxCloseErrors = 10
+
+// DefStats is what the admin endpoint reports for one definition.
+// Dependencies are discovered when the definition is built,
+// so they are empty until the first build.
+type DefStats struct {
+	Name           string   `json:"name"`
+	Scope          string   `json:"scope"`
+	Builds         uint64   `json:"builds"`
+	BuildErrors    uint64   `json:"buildErrors"`
+	LastBuildTime  string   `json:"lastBuildTime"`
+	TotalBuildTime string   `json:"totalBuildTime"`
+	Closes         uint64   `json:"closes"`
+	CloseErrors    []string `json:"closeErrors"`
+	Dependencies   []string `json:"dependencies"`
+}
+
+type defStats struct {
+	scope          string
+	builds         uint64
+	buildErrors    uint64
+	lastBuildTime  time.Duration
+	totalBuildTime time.Duration
+	closes         uint64
+	closeErrors    []string
+	dependencies   map[string]bool
+}
+
+type appObject struct {
+	name  string
+	obj   interface{}
+	close func(obj interface{}) error
+}
+
+// Registry instruments di definitions to collect build and close statistics,
+// the dependency graph between definitions, and to close the app scoped
+// objects in the reverse order of their creation.
+type Registry struct {
+	mu      sync.Mutex
+	logger  *zap.Logger
+	stats   map[string]*defStats
+	app     []appObject
+	closing bool
+}
+
+func NewRegistry(logger *zap.Logger) *Registry {
+	return &Registry{
+		logger: logger,
+		stats:  map[string]*defStats{},
+	}
+}
+
+// Def exposes the Registry itself in the container as `di-diagnostics`.
+func (r *Registry) Def() di.Def {
+	return di.Def{
+		Name:  "di-diagnostics",
+		Scope: di.App,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return r, nil
+		},
+	}
+}
+
+// Instrument returns copies of defs with Build and Close wrapped.
+func (r *Registry) Instrument(defs ...di.Def) []di.Def {
+	instrumented := make([]di.Def, 0, len(defs))
+	for _, def := range defs {
+		scope := def.Scope
+		if scope == "" {
+			scope = di.App
+		}
+		r.stats[def.Name] = &defStats{scope: scope, dependencies: map[string]bool{}}
+		instrumented = append(instrumented, r.instrument(def, scope))
+	}
+	return instrumented
+}
+
+func (r *Registry) instrument(def di.Def, scope string) di.Def {
+	build, closeFunc, name := def.Build, def.Close, def.Name
+
+	def.Build = func(ctn di.Container) (interface{}, error) {
+		start := time.Now()
+		obj, err := build(&recordingContainer{Container: ctn, registry: r, from: name})
+		elapsed := time.Since(start)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		s := r.stats[name]
+		if err != nil {
+			s.buildErrors++
+			return obj, err
+		}
+		s.builds++
+		s.lastBuildTime = elapsed
+		s.totalBuildTime += elapsed
+		if scope == di.App {
+			r.app = append(r.app, appObject{name: name, obj: obj, close: closeFunc})
+		}
+		return obj, nil
+	}
+
+	def.Close = func(obj interface{}) error {
+		if scope == di.App {
+			r.mu.Lock()
+			closing := r.closing
+			r.mu.Unlock()
+			// Shutdown closes them itself, in order
+			if closing {
+				return nil
+			}
+		}
+		return r.close(name, obj, closeFunc)
+	}
+	return def
+}
+
+func (r *Registry) close(name string, obj interface{}, closeFunc func(obj interface{}) error) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("Close function panicked: %v", rec)
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		s := r.stats[name]
+		s.closes++
+		if err != nil {
+			s.closeErrors = append(s.closeErrors, err.Error())
+			if len(s.closeErrors) > maxCloseErrors {
+				s.closeErrors = s.closeErrors[1:]
+			}
+		}
+	}()
+
+	if closeFunc != nil {
+		err = closeFunc(obj)
+	}
+	return err
+}
+
+func (r *Registry) addDependency(from, to string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.stats[from]; ok {
+		s.dependencies[to] = true
+	}
+}
+
+// Shutdown deletes app and its sub-containers. The request scoped objects
+// are closed first, then the app scoped ones from the last built to the first,
+// so that nothing is closed before the objects depending on it.
+// Every close error is logged and the first one is returned.
+func (r *Registry) Shutdown(app di.Container) error {
+	r.mu.Lock()
+	r.closing = true
+	r.mu.Unlock()
+
+	var errs []string
+	if err := app.DeleteWithSubContainers(); err != nil {
+		r.logger.Error("could not delete the container", zap.Error(err))
+		errs = append(errs, err.Error())
+	}
+
+	r.mu.Lock()
+	objects := r.app
+	r.app = nil
+	r.mu.Unlock()
+
+	for i := len(objects) - 1; i >= 0; i-- {
+		o := objects[i]
+		if err := r.close(o.name, o.obj, o.close); err != nil {
+			r.logger.Error("could not close `"+o.name+"`", zap.Error(err))
+			errs = append(errs, o.name+": "+err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Stats returns the statistics of every definition, sorted by name.
+func (r *Registry) Stats() []DefStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := make([]DefStats, 0, len(r.stats))
+	for name, s := range r.stats {
+		all = append(all, DefStats{
+			Name:           name,
+			Scope:          s.scope,
+			Builds:         s.builds,
+			BuildErrors:    s.buildErrors,
+			LastBuildTime:  s.lastBuildTime.String(),
+			TotalBuildTime: s.totalBuildTime.String(),
+			Closes:         s.closes,
+			CloseErrors:    append([]string{}, s.closeErrors...),
+			Dependencies:   sortedKeys(s.dependencies),
+		})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// WriteDOT writes the dependency graph in the Graphviz DOT format.
+// Nodes are grouped by scope.
+func (r *Registry) WriteDOT(w io.Writer) error {
+	stats := r.Stats()
+	scopes := map[string][]string{}
+	scopeNames := map[string]bool{}
+	for _, s := range stats {
+		scopes[s.Scope] = append(scopes[s.Scope], s.Name)
+		scopeNames[s.Scope] = true
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph di {\n")
+	b.WriteString("\trankdir=LR;\n")
+	for _, scope := range sortedKeys(scopeNames) {
+		fmt.Fprintf(&b, "\tsubgraph %q {\n", "cluster_"+scope)
+		fmt.Fprintf(&b, "\t\tlabel=%q;\n", scope)
+		for _, name := range scopes[scope] {
+			fmt.Fprintf(&b, "\t\t%q;\n", name)
+		}
+		b.WriteString("\t}\n")
+	}
+	for _, s := range stats {
+		for _, dep := range s.Dependencies {
+			fmt.Fprintf(&b, "\t%q -> %q;\n", s.Name, dep)
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// recordingContainer is handed to the Build functions
+// to find out which definitions they depend on.
+type recordingContainer struct {
+	di.Container
+	registry *Registry
+	from     string
+}
+
+func (c *recordingContainer) Get(name string) interface{} {
+	c.registry.addDependency(c.from, name)
+	return c.Container.Get(name)
+}
+
+func (c *recordingContainer) SafeGet(name string) (interface{}, error) {
+	c.registry.addDependency(c.from, name)
+	return c.Container.SafeGet(name)
+}
+
+func (c *recordingContainer) Fill(name string, dst interface{}) error {
+	c.registry.addDependency(c.from, name)
+	return c.Container.Fill(name, dst)
+}
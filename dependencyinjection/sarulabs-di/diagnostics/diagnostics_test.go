@@ -0,0 +1,97 @@
+package diagnostics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sarulabs/di"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func buildContainer(t *testing.T, registry *Registry, closed *[]string) di.Container {
+	builder, err := di.NewBuilder()
+	require.NoError(t, err)
+
+	closer := func(name string, err error) func(obj interface{}) error {
+		return func(obj interface{}) error {
+			*closed = append(*closed, name)
+			return err
+		}
+	}
+	err = builder.Add(registry.Instrument(
+		di.Def{
+			Name:  "pool",
+			Scope: di.App,
+			Build: func(ctn di.Container) (interface{}, error) { return "pool", nil },
+			Close: closer("pool", nil),
+		},
+		di.Def{
+			Name:  "cache",
+			Scope: di.App,
+			Build: func(ctn di.Container) (interface{}, error) { return ctn.Get("pool"), nil },
+			Close: closer("cache", errors.New("boom")),
+		},
+		di.Def{
+			Name:  "session",
+			Scope: di.Request,
+			Build: func(ctn di.Container) (interface{}, error) { return ctn.Get("cache"), nil },
+			Close: closer("session", nil),
+		},
+	)...)
+	require.NoError(t, err)
+	return builder.Build()
+}
+
+func TestRegistry_Stats(t *testing.T) {
+	var closed []string
+	registry := NewRegistry(zap.NewNop())
+	app := buildContainer(t, registry, &closed)
+
+	for i := 0; i < 3; i++ {
+		req, err := app.SubContainer()
+		require.NoError(t, err)
+		req.Get("session")
+		require.NoError(t, req.Delete())
+	}
+
+	stats := registry.Stats()
+	require.Len(t, stats, 3)
+	assert.Equal(t, "cache", stats[0].Name)
+	assert.Equal(t, uint64(1), stats[0].Builds)
+	assert.Equal(t, []string{"pool"}, stats[0].Dependencies)
+	assert.Equal(t, "session", stats[2].Name)
+	assert.Equal(t, di.Request, stats[2].Scope)
+	assert.Equal(t, uint64(3), stats[2].Builds)
+	assert.Equal(t, uint64(3), stats[2].Closes)
+	assert.Equal(t, []string{"cache"}, stats[2].Dependencies)
+}
+
+func TestRegistry_ShutdownOrder(t *testing.T) {
+	var closed []string
+	registry := NewRegistry(zap.NewNop())
+	app := buildContainer(t, registry, &closed)
+
+	req, err := app.SubContainer()
+	require.NoError(t, err)
+	req.Get("session")
+
+	err = registry.Shutdown(app)
+	assert.EqualError(t, err, "cache: boom")
+	assert.Equal(t, []string{"session", "cache", "pool"}, closed)
+	assert.Equal(t, []string{"boom"}, registry.Stats()[0].CloseErrors)
+}
+
+func TestRegistry_WriteDOT(t *testing.T) {
+	var closed []string
+	registry := NewRegistry(zap.NewNop())
+	app := buildContainer(t, registry, &closed)
+	app.Get("cache")
+
+	var b strings.Builder
+	require.NoError(t, registry.WriteDOT(&b))
+	assert.Contains(t, b.String(), `"cache" -> "pool";`)
+	assert.Contains(t, b.String(), `label="request";`)
+}
@@ -0,0 +1,10 @@
+package garage
+
+// Cache stores serialized values by key.
+// LRUCache is the in-process implementation; a shared cache
+// (redis, memcached, ...) only has to satisfy this interface.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Delete(keys ...string)
+}
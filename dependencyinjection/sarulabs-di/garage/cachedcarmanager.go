@@ -0,0 +1,157 @@
+package garage
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	carsCacheKey = "cars:all"
+	carKeyPrefix = "car:"
+)
+
+// Manager is what the handlers need from a car manager.
+// It is implemented by CarManager and by the CachedCarManager decorator.
+type Manager interface {
+	GetAll() (*[]Car, error)
+	Get(id string) (*Car, error)
+	Create(car *Car) (*Car, error)
+	Update(id string, car *Car) (*Car, error)
+	Delete(id string) error
+}
+
+// CacheStats holds the hit/miss counters of a CarCache.
+type CacheStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+// CarCache is the long-lived part of the caching layer.
+// CarManagers are built per request, so the cache, the singleflight group
+// and the counters have to live in the app scope and be shared.
+type CarCache struct {
+	Cache  Cache
+	group  singleflight.Group
+	hits   uint64
+	misses uint64
+
+	// generation is bumped by every invalidation, a load started before one
+	// neither stores its result nor is shared with the loads started after.
+	mu         sync.Mutex
+	generation uint64
+}
+
+func NewCarCache(cache Cache) *CarCache {
+	return &CarCache{Cache: cache}
+}
+
+func (c *CarCache) Stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+	}
+}
+
+// load returns the cached value for key decoded into out.
+// On a miss, fetch is called once for all the concurrent callers
+// asking for the same key and its result is stored in the cache.
+func (c *CarCache) load(key string, out interface{}, fetch func() (interface{}, error)) error {
+	if data, ok := c.Cache.Get(key); ok {
+		atomic.AddUint64(&c.hits, 1)
+		return json.Unmarshal(data, out)
+	}
+	atomic.AddUint64(&c.misses, 1)
+
+	c.mu.Lock()
+	generation := c.generation
+	c.mu.Unlock()
+	data, err, _ := c.group.Do(key+"@"+strconv.FormatUint(generation, 10), func() (interface{}, error) {
+		value, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.generation == generation {
+			c.Cache.Set(key, data)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data.([]byte), out)
+}
+
+func (c *CarCache) invalidate(ids ...string) {
+	keys := []string{carsCacheKey}
+	for _, id := range ids {
+		keys = append(keys, carKeyPrefix+id)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.Cache.Delete(keys...)
+}
+
+// CachedCarManager is a read-through cache in front of a Manager.
+// Get and GetAll are served from the cache, writes go to the
+// underlying Manager and invalidate the affected entries.
+type CachedCarManager struct {
+	Manager Manager
+	Cache   *CarCache
+}
+
+func (m *CachedCarManager) GetAll() (*[]Car, error) {
+	var cars []Car
+	err := m.Cache.load(carsCacheKey, &cars, func() (interface{}, error) {
+		return m.Manager.GetAll()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cars, nil
+}
+
+func (m *CachedCarManager) Get(id string) (*Car, error) {
+	var car Car
+	err := m.Cache.load(carKeyPrefix+id, &car, func() (interface{}, error) {
+		return m.Manager.Get(id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+func (m *CachedCarManager) Create(car *Car) (*Car, error) {
+	car, err := m.Manager.Create(car)
+	if err == nil {
+		m.Cache.invalidate()
+	}
+	return car, err
+}
+
+func (m *CachedCarManager) Update(id string, car *Car) (*Car, error) {
+	car, err := m.Manager.Update(id, car)
+	if err == nil {
+		m.Cache.invalidate(id, car.ID.Hex())
+	}
+	return car, err
+}
+
+func (m *CachedCarManager) Delete(id string) error {
+	err := m.Manager.Delete(id)
+	if err == nil {
+		m.Cache.invalidate(id)
+	}
+	return err
+}
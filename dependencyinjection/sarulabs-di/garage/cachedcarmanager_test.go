@@ -0,0 +1,110 @@
+package garage
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pwera/di/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// countingManager counts the GetAll calls reaching the repository, release
+// holds them until it is closed when set.
+type countingManager struct {
+	Manager
+	calls   int32
+	release chan struct{}
+}
+
+func (m *countingManager) GetAll() (*[]Car, error) {
+	atomic.AddInt32(&m.calls, 1)
+	cars, err := m.Manager.GetAll()
+	if m.release != nil {
+		<-m.release
+	}
+	return cars, err
+}
+
+func newCachedManager() (*CachedCarManager, *countingManager) {
+	counting := &countingManager{Manager: &CarManager{Repo: NewMemoryCarRepository(), Logger: zap.NewNop()}}
+	return &CachedCarManager{Manager: counting, Cache: NewCarCache(NewLRUCache(10, time.Minute))}, counting
+}
+
+func TestCachedCarManager_Counters(t *testing.T) {
+	m, counting := newCachedManager()
+
+	for i := 0; i < 3; i++ {
+		_, err := m.GetAll()
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), counting.calls)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1}, m.Cache.Stats())
+}
+
+func TestCachedCarManager_Singleflight(t *testing.T) {
+	m, counting := newCachedManager()
+	counting.release = make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := m.GetAll()
+			assert.NoError(t, err)
+		}()
+	}
+	assert.Eventually(t, func() bool { return m.Cache.Stats().Misses == 10 }, time.Second, time.Millisecond)
+	close(counting.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), counting.calls)
+}
+
+func TestCachedCarManager_InvalidationDuringLoad(t *testing.T) {
+	m, counting := newCachedManager()
+	counting.release = make(chan struct{})
+
+	loaded := make(chan *[]Car)
+	go func() {
+		cars, err := m.GetAll()
+		assert.NoError(t, err)
+		loaded <- cars
+	}()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&counting.calls) == 1 }, time.Second, time.Millisecond)
+
+	// The car is created while the load above holds the empty list.
+	_, err := m.Create(&Car{Brand: "bmw", Color: "red"})
+	require.NoError(t, err)
+	close(counting.release)
+	assert.Empty(t, *<-loaded)
+
+	cars, err := m.GetAll()
+	require.NoError(t, err)
+	assert.Len(t, *cars, 1, "the stale load was not cached")
+}
+
+func TestCachedCarManager_Update(t *testing.T) {
+	m, _ := newCachedManager()
+	car, err := m.Create(&Car{Brand: "bmw", Color: "red"})
+	require.NoError(t, err)
+	other, err := m.Create(&Car{Brand: "audi", Color: "black"})
+	require.NoError(t, err)
+	id := car.ID.Hex()
+	_, err = m.Get(id)
+	require.NoError(t, err)
+
+	_, err = m.Update(id, &Car{ID: other.ID, Brand: "bmw", Color: "white"})
+	assert.IsType(t, &helpers.ErrValidation{}, err)
+
+	_, err = m.Update(id, &Car{Brand: "bmw", Color: "white"})
+	require.NoError(t, err)
+	cached, err := m.Get(id)
+	require.NoError(t, err)
+	assert.Equal(t, "white", cached.Color)
+}
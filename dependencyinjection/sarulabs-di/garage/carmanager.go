@@ -2,12 +2,14 @@ package garage
 
 import (
 	"github.com/pwera/di/helpers"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/zap"
 )
 
 type CarManager struct {
-	Repo   *CarRepository
+	Repo   Repository
 	Logger *zap.Logger
+	Events *EventBus
 }
 
 func (m *CarManager) GetAll() (*[]Car, error) {
@@ -36,20 +38,37 @@ func (m *CarManager) Get(id string) (car *Car, err error) {
 }
 
 func (m *CarManager) Create(car *Car) (*Car, error) {
-	_, err := m.Repo.collection().InsertOne(nil, &car)
+	if err := ValidateCar(car); err != nil {
+		return nil, err
+	}
+
+	err := m.Repo.Insert(car)
 	if err != nil {
+		m.Logger.Error(err.Error())
 		return nil, err
 	}
 
+	m.publish(CarCreated, car.ID.Hex(), car)
 	return car, err
 }
 
+// Update writes the car with the given id, the id in the car may be left
+// empty but can't name another car.
 func (m *CarManager) Update(id string, car *Car) (*Car, error) {
 	if err := ValidateCar(car); err != nil {
 		return nil, err
 	}
 
-	err := m.Repo.Update(car)
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, helpers.NewErrNotFound("Car " + id + " does not exist")
+	}
+	if !car.ID.IsZero() && car.ID != objectID {
+		return nil, helpers.NewErrValidation("Car id `" + car.ID.Hex() + "` does not match `" + id + "`")
+	}
+	car.ID = objectID
+
+	err = m.Repo.Update(car)
 
 	if m.Repo.IsNotFoundErr(err) {
 		return nil, helpers.NewErrNotFound("Car " + id + " does not exist")
@@ -59,6 +78,8 @@ func (m *CarManager) Update(id string, car *Car) (*Car, error) {
 		m.Logger.Error(err.Error())
 		return nil, err
 	}
+
+	m.publish(CarUpdated, id, car)
 	return car, err
 }
 
@@ -70,6 +91,15 @@ func (m *CarManager) Delete(id string) error {
 	}
 	if err != nil {
 		m.Logger.Error(err.Error())
+		return err
+	}
+
+	m.publish(CarDeleted, id, nil)
+	return nil
+}
+
+func (m *CarManager) publish(t CarEventType, id string, car *Car) {
+	if m.Events != nil {
+		m.Events.Publish(CarEvent{Type: t, ID: id, Car: car})
 	}
-	return err
 }
@@ -3,11 +3,24 @@ package garage
 import (
 	"context"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
 )
 
+// Repository is the storage used by CarManager.
+// CarRepository stores the cars in mongo, MemoryCarRepository in memory.
+type Repository interface {
+	FindAll() (*[]Car, error)
+	FindByID(id string) (*Car, error)
+	Insert(car *Car) error
+	Update(car *Car) error
+	Delete(id string) error
+	IsNotFoundErr(err error) bool
+	IsAlreadyExistErr(err error) bool
+}
+
 type CarRepository struct {
 	Client *mongo.Client
 }
@@ -38,8 +51,14 @@ func (repo *CarRepository) FindByID(id string) (*Car, error) {
 }
 
 func (repo *CarRepository) Insert(car *Car) error {
-	_, err := repo.collection().InsertOne(nil, &car)
-	return err
+	res, err := repo.collection().InsertOne(nil, &car)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(primitive.ObjectID); ok {
+		car.ID = id
+	}
+	return nil
 }
 
 func (repo *CarRepository) Update(car *Car) error {
@@ -47,9 +66,14 @@ func (repo *CarRepository) Update(car *Car) error {
 	update := bson.D{{"$set", bson.D{{"brand", car.Brand},
 		{"color", car.Color}}}}
 
-	_, err := repo.collection().UpdateOne(nil, filter, update)
-
-	return err
+	res, err := repo.collection().UpdateOne(nil, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
 }
 
 func (repo *CarRepository) Delete(id string) error {
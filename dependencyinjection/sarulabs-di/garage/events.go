@@ -0,0 +1,69 @@
+package garage
+
+import (
+	"sync"
+	"time"
+)
+
+type CarEventType string
+
+const (
+	CarCreated CarEventType = "car.created"
+	CarUpdated CarEventType = "car.updated"
+	CarDeleted CarEventType = "car.deleted"
+)
+
+// CarEvent describes a change made through a CarManager.
+// Car is nil for deletions.
+type CarEvent struct {
+	Type CarEventType `json:"type"`
+	ID   string       `json:"id"`
+	Car  *Car         `json:"car,omitempty"`
+	Time time.Time    `json:"time"`
+}
+
+// EventBus fans out car events to every subscriber.
+// Publishing never blocks: a subscriber whose buffer is full misses the event.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[chan CarEvent]struct{}
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: map[chan CarEvent]struct{}{}}
+}
+
+// Subscribe returns a channel receiving the published events
+// and a function to call once the subscriber is done.
+func (b *EventBus) Subscribe(buffer int) (<-chan CarEvent, func()) {
+	ch := make(chan CarEvent, buffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (b *EventBus) Publish(event CarEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package garage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRUCache(2, time.Minute)
+	c.Set("a", []byte("1"))
+	c.Set("b", []byte("2"))
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	c.Set("c", []byte("3"))
+
+	assert.Equal(t, 2, c.Len())
+	_, ok = c.Get("b")
+	assert.False(t, ok, "b was the least recently used")
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), v)
+}
+
+func TestLRUCache_TTL(t *testing.T) {
+	now := time.Now()
+	c := NewLRUCache(10, time.Minute)
+	c.now = func() time.Time { return now }
+	c.Set("a", []byte("1"))
+
+	now = now.Add(59 * time.Second)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len(), "expired entries are dropped")
+}
+
+func TestLRUCache_Delete(t *testing.T) {
+	c := NewLRUCache(10, time.Minute)
+	c.Set("a", []byte("1"))
+	c.Set("b", []byte("2"))
+
+	c.Delete("a", "missing")
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.Len())
+}
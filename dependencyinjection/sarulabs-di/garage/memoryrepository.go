@@ -0,0 +1,86 @@
+package garage
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errCarNotFound = errors.New("car not found")
+
+// MemoryCarRepository is a Repository keeping the cars in a map.
+// It is meant for tests and for running the API without mongo.
+type MemoryCarRepository struct {
+	mu   sync.RWMutex
+	cars map[string]Car
+}
+
+func NewMemoryCarRepository() *MemoryCarRepository {
+	return &MemoryCarRepository{cars: map[string]Car{}}
+}
+
+func (repo *MemoryCarRepository) FindAll() (*[]Car, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	cars := make([]Car, 0, len(repo.cars))
+	for _, car := range repo.cars {
+		cars = append(cars, car)
+	}
+	sort.Slice(cars, func(i, j int) bool { return cars[i].ID.Hex() < cars[j].ID.Hex() })
+	return &cars, nil
+}
+
+func (repo *MemoryCarRepository) FindByID(id string) (*Car, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	car, ok := repo.cars[id]
+	if !ok {
+		return nil, errCarNotFound
+	}
+	return &car, nil
+}
+
+func (repo *MemoryCarRepository) Insert(car *Car) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if car.ID.IsZero() {
+		car.ID = primitive.NewObjectID()
+	}
+	repo.cars[car.ID.Hex()] = *car
+	return nil
+}
+
+func (repo *MemoryCarRepository) Update(car *Car) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.cars[car.ID.Hex()]; !ok {
+		return errCarNotFound
+	}
+	repo.cars[car.ID.Hex()] = *car
+	return nil
+}
+
+func (repo *MemoryCarRepository) Delete(id string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.cars[id]; !ok {
+		return errCarNotFound
+	}
+	delete(repo.cars, id)
+	return nil
+}
+
+func (repo *MemoryCarRepository) IsNotFoundErr(err error) bool {
+	return errors.Is(err, errCarNotFound)
+}
+
+func (repo *MemoryCarRepository) IsAlreadyExistErr(err error) bool {
+	return false
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pwera/di/diagnostics"
+	"github.com/pwera/di/helpers"
+	"github.com/sarulabs/di"
+)
+
+// GetDIDefinitionsHandler is the handler that lists the container definitions
+// with their scope, build and close statistics.
+func GetDIDefinitionsHandler(w http.ResponseWriter, r *http.Request) {
+	registry := di.Get(r, "di-diagnostics").(*diagnostics.Registry)
+	helpers.JSONResponse(w, 200, registry.Stats())
+}
+
+// GetDIGraphHandler is the handler that exports the dependency graph in DOT format.
+func GetDIGraphHandler(w http.ResponseWriter, r *http.Request) {
+	registry := di.Get(r, "di-diagnostics").(*diagnostics.Registry)
+	w.Header().Set("Content-Type", "text/vnd.graphviz")
+	w.WriteHeader(200)
+	registry.WriteDOT(w)
+}
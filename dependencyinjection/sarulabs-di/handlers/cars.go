@@ -12,7 +12,7 @@ import (
 )
 
 func GetCarListHandler(w http.ResponseWriter, r *http.Request) {
-	manager := di.Get(r, "car-manager").(*garage.CarManager)
+	manager := di.Get(r, "car-manager").(garage.Manager)
 	cars, err := manager.GetAll()
 
 	if err == nil {
@@ -37,7 +37,7 @@ func PostCarHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	now := time.Now()
-	manager := di.Get(r, "car-manager").(*garage.CarManager)
+	manager := di.Get(r, "car-manager").(garage.Manager)
 	car, err := manager.Create(input)
 	fmt.Println("Times spend here {}", time.Now().Sub(now).String())
 
@@ -62,7 +62,7 @@ func PostCarHandler(w http.ResponseWriter, r *http.Request) {
 func GetCarHandler(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["carId"]
 
-	manager := di.Get(r, "car-manager").(*garage.CarManager)
+	manager := di.Get(r, "car-manager").(garage.Manager)
 	car, err := manager.Get(id)
 
 	if err == nil {
@@ -96,7 +96,7 @@ func PutCarHandler(w http.ResponseWriter, r *http.Request) {
 
 	id := mux.Vars(r)["carId"]
 
-	manager := di.Get(r, "car-manager").(*garage.CarManager)
+	manager := di.Get(r, "car-manager").(garage.Manager)
 	car, err := manager.Update(id, input)
 
 	if err == nil {
@@ -124,7 +124,7 @@ func PutCarHandler(w http.ResponseWriter, r *http.Request) {
 func DeleteCarHandler(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["carId"]
 
-	manager := di.Get(r, "car-manager").(*garage.CarManager)
+	manager := di.Get(r, "car-manager").(garage.Manager)
 	err := manager.Delete(id)
 
 	if err == nil {
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pwera/di/helpers"
+	"github.com/pwera/di/webhooks"
+	"github.com/sarulabs/di"
+)
+
+// GetWebhookListHandler is the handler that lists the webhook subscriptions.
+func GetWebhookListHandler(w http.ResponseWriter, r *http.Request) {
+	registry := di.Get(r, "webhook-registry").(*webhooks.Registry)
+	helpers.JSONResponse(w, 200, registry.All())
+}
+
+// PostWebhookHandler is the handler that registers a new webhook.
+// The response is the only one containing the signing secret.
+func PostWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var input *webhooks.Subscription
+
+	err := helpers.ReadJSONBody(r, &input)
+	if err != nil || input == nil {
+		helpers.JSONResponse(w, 400, map[string]interface{}{
+			"error": "Could not decode request body.",
+		})
+		return
+	}
+
+	registry := di.Get(r, "webhook-registry").(*webhooks.Registry)
+	subscription, err := registry.Add(input)
+
+	if err == nil {
+		helpers.JSONResponse(w, 200, subscription)
+		return
+	}
+
+	writeWebhookError(w, err)
+}
+
+// GetWebhookHandler is the handler that prints a webhook subscription.
+func GetWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["webhookId"]
+
+	registry := di.Get(r, "webhook-registry").(*webhooks.Registry)
+	subscription, err := registry.Get(id)
+
+	if err == nil {
+		helpers.JSONResponse(w, 200, subscription)
+		return
+	}
+
+	writeWebhookError(w, err)
+}
+
+// DeleteWebhookHandler is the handler that removes a webhook subscription.
+func DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["webhookId"]
+
+	registry := di.Get(r, "webhook-registry").(*webhooks.Registry)
+	err := registry.Delete(id)
+
+	if err == nil {
+		w.WriteHeader(204)
+		return
+	}
+
+	writeWebhookError(w, err)
+}
+
+// GetWebhookDeliveriesHandler is the handler that prints the delivery log of a webhook.
+func GetWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["webhookId"]
+
+	registry := di.Get(r, "webhook-registry").(*webhooks.Registry)
+	deliveries, err := registry.Deliveries(id)
+
+	if err == nil {
+		helpers.JSONResponse(w, 200, deliveries)
+		return
+	}
+
+	writeWebhookError(w, err)
+}
+
+// GetWebhookDeadLettersHandler is the handler that lists the events
+// that could not be delivered.
+func GetWebhookDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	registry := di.Get(r, "webhook-registry").(*webhooks.Registry)
+	helpers.JSONResponse(w, 200, registry.DeadLetters())
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *helpers.ErrValidation:
+		helpers.JSONResponse(w, 400, map[string]interface{}{
+			"error": e.Error(),
+		})
+	case *helpers.ErrNotFound:
+		helpers.JSONResponse(w, 404, map[string]interface{}{
+			"error": e.Error(),
+		})
+	default:
+		helpers.JSONResponse(w, 500, map[string]interface{}{
+			"error": "Internal Error",
+		})
+	}
+}
@@ -9,8 +9,8 @@ import (
 
 func JSONResponse(w http.ResponseWriter, status int, data interface{}) {
 	resp, _ := json.Marshal(data)
-	w.WriteHeader(status)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	w.Write(resp)
 }
 
@@ -2,19 +2,25 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"github.com/gorilla/mux"
-	"github.com/pwera/di/handlers"
+	"github.com/pwera/di/diagnostics"
+	"github.com/pwera/di/garage"
 	"github.com/pwera/di/logging"
-	"github.com/pwera/di/middlewares"
+	"github.com/pwera/di/protocol"
+	"github.com/pwera/di/rpc"
 	"github.com/pwera/di/services"
 	"github.com/sarulabs/di"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
@@ -31,35 +37,23 @@ func main() {
 		logging.Logger.Fatal(err.Error())
 	}
 
-	err = builder.Add(services.Services...)
+	registry := diagnostics.NewRegistry(logging.Logger)
+	err = builder.Add(registry.Instrument(append(services.Services, registry.Def())...)...)
 	if err != nil {
 		logging.Logger.Fatal(err.Error())
 	}
 
 	app := builder.Build()
-	defer func() {
-		if err2 := app.Delete(); err2 != nil {
-			fmt.Println(err)
-		}
-	}()
 
-	r := mux.NewRouter()
+	r := newRouter(app, logging.Logger)
 
-	m := func(h http.HandlerFunc) http.HandlerFunc {
-		return middlewares.PanicRecoveryMiddleware(
-			di.HTTPMiddleware(h, app, func(msg string) {
-				logging.Logger.Error(msg)
-			}),
-			logging.Logger,
-		)
-	}
-	//manager := di.Get(r, "car-manager").(*garage.CarManager)
-	//manager.GetAll()
-	r.HandleFunc("/cars", m(handlers.GetCarListHandler)).Methods("GET")
-	r.HandleFunc("/cars", m(handlers.PostCarHandler)).Methods("POST")
-	r.HandleFunc("/cars/{carId}", m(handlers.GetCarHandler)).Methods("GET")
-	r.HandleFunc("/cars/{carId}", m(handlers.PutCarHandler)).Methods("PUT")
-	r.HandleFunc("/cars/{carId}", m(handlers.DeleteCarHandler)).Methods("DELETE")
+	// the dispatcher only listens to the car events once it is built
+	app.Get("webhook-dispatcher")
+
+	carCache := app.Get("car-cache").(*garage.CarCache)
+	expvar.Publish("car_cache", expvar.Func(func() interface{} {
+		return carCache.Stats()
+	}))
 
 	port := "8080"
 	srv := &http.Server{
@@ -76,6 +70,26 @@ func main() {
 		}
 	}()
 
+	grpcPort := "9090"
+	lis, err := net.Listen("tcp", "0.0.0.0:"+grpcPort)
+	if err != nil {
+		logging.Logger.Fatal(err.Error())
+	}
+	grpcServer := grpc.NewServer()
+	reflection.Register(grpcServer)
+	protocol.RegisterCarServiceServer(grpcServer, &rpc.CarServer{
+		App:    app,
+		Events: app.Get("car-events").(*garage.EventBus),
+		Logger: logging.Logger,
+	})
+	logging.Logger.Info("Listening for gRPC on port " + grpcPort)
+
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			logging.Logger.Error(err.Error())
+		}
+	}()
+
 	stop := make(chan os.Signal, 1)
 
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
@@ -89,4 +103,24 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		logging.Logger.Error(err.Error())
 	}
+
+	logging.Logger.Info("Stopping the gRPC server")
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		// WatchCars streams only end when their client leaves
+		logging.Logger.Warn("the gRPC server did not drain in time, stopping the remaining calls")
+		grpcServer.Stop()
+	}
+
+	// no request is in flight anymore, the container can be closed
+	logging.Logger.Info("Closing the container")
+	if err := registry.Shutdown(app); err != nil {
+		logging.Logger.Error("container closed with errors", zap.Error(err))
+	}
 }
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pwera/di/diagnostics"
+	"github.com/pwera/di/garage"
+	"github.com/pwera/di/services"
+	"github.com/sarulabs/di"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const seededCarID = "64777732f299590f2a62ffe7"
+
+type harness struct {
+	app    di.Container
+	router http.Handler
+	repo   *garage.MemoryCarRepository
+}
+
+// newHarness builds the real router and container, instrumented like in main,
+// with the mongo definitions replaced by an in-memory car repository.
+// overrides replace the definitions with the same name.
+func newHarness(t *testing.T, overrides ...di.Def) *harness {
+	repo := garage.NewMemoryCarRepository()
+	id, _ := primitive.ObjectIDFromHex(seededCarID)
+	require.NoError(t, repo.Insert(&garage.Car{ID: id, Brand: "bmw", Color: "red"}))
+
+	overrides = append(overrides, di.Def{
+		Name:  "car-repository",
+		Scope: di.App,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return repo, nil
+		},
+	})
+	replaced := map[string]bool{"mongo-pool": true, "mongo": true}
+	var defs []di.Def
+	for _, def := range overrides {
+		if !replaced[def.Name] {
+			replaced[def.Name] = true
+			defs = append(defs, def)
+		}
+	}
+	for _, def := range services.Services {
+		if !replaced[def.Name] {
+			defs = append(defs, def)
+		}
+	}
+
+	builder, err := di.NewBuilder()
+	require.NoError(t, err)
+	registry := diagnostics.NewRegistry(zap.NewNop())
+	require.NoError(t, builder.Add(registry.Instrument(append(defs, registry.Def())...)...))
+	app := builder.Build()
+	t.Cleanup(func() {
+		assert.NoError(t, app.Delete())
+	})
+
+	return &harness{
+		app:    app,
+		router: newRouter(app, zap.NewNop()),
+		repo:   repo,
+	}
+}
+
+func (h *harness) do(method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCarRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		contains string
+	}{
+		{"list", "GET", "/cars", "", 200, `"brand":"bmw"`},
+		{"get", "GET", "/cars/" + seededCarID, "", 200, `"id":"` + seededCarID + `"`},
+		{"get zero id", "GET", "/cars/000000000000000000000000", "", 404, "does not exist"},
+		{"get unknown", "GET", "/cars/123456", "", 404, "Car 123456 does not exist"},
+		{"create", "POST", "/cars", `{"brand": "bmw", "color": "red"}`, 200, `"color":"red"`},
+		{"create bad json", "POST", "/cars", `{"brand": `, 400, "Could not decode request body."},
+		{"create unknown brand", "POST", "/cars", `{"brand": "fiat", "color": "red"}`, 400, "Brand `fiat` does not exist"},
+		{"create unknown color", "POST", "/cars", `{"brand": "bmw", "color": "pink"}`, 400, "Color `pink` does not exist for `bmw`"},
+		{"update", "PUT", "/cars/" + seededCarID, `{"id": "` + seededCarID + `", "brand": "porsche", "color": "black"}`, 200, `"brand":"porsche"`},
+		{"update bad json", "PUT", "/cars/" + seededCarID, `[`, 400, "Could not decode request body."},
+		{"update invalid", "PUT", "/cars/" + seededCarID, `{"id": "` + seededCarID + `", "brand": "porsche", "color": "white"}`, 400, "Color `white` does not exist"},
+		{"update unknown", "PUT", "/cars/000000000000000000000001", `{"id": "000000000000000000000001", "brand": "audi", "color": "white"}`, 404, "does not exist"},
+		{"delete", "DELETE", "/cars/" + seededCarID, "", 204, ""},
+		{"delete unknown", "DELETE", "/cars/123456", "", 204, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			rec := h.do(tt.method, tt.path, tt.body)
+
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+			assert.Contains(t, rec.Body.String(), tt.contains)
+			if rec.Code != 204 {
+				assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestCarRoutes_ReadAfterWrite(t *testing.T) {
+	h := newHarness(t)
+
+	// warm the cache, then make sure the writes invalidate it
+	require.Equal(t, 200, h.do("GET", "/cars/"+seededCarID, "").Code)
+	require.Equal(t, 200, h.do("PUT", "/cars/"+seededCarID, `{"id": "`+seededCarID+`", "brand": "audi", "color": "yellow"}`).Code)
+
+	rec := h.do("GET", "/cars/"+seededCarID, "")
+	assert.Contains(t, rec.Body.String(), `"brand":"audi"`)
+
+	require.Equal(t, 204, h.do("DELETE", "/cars/"+seededCarID, "").Code)
+	assert.Equal(t, 404, h.do("GET", "/cars/"+seededCarID, "").Code)
+	assert.Equal(t, "[]", h.do("GET", "/cars", "").Body.String())
+}
+
+func TestPanicRecovery(t *testing.T) {
+	h := newHarness(t, di.Def{
+		Name:  "car-repository",
+		Scope: di.Request,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return nil, errors.New("mongo is down")
+		},
+	})
+
+	for _, path := range []string{"/cars", "/cars/" + seededCarID} {
+		rec := h.do("GET", path, "")
+		assert.Equal(t, 500, rec.Code, path)
+		assert.JSONEq(t, `{"error": "Internal Error"}`, rec.Body.String(), path)
+	}
+}
+
+func TestWebhookRoutes(t *testing.T) {
+	h := newHarness(t)
+
+	rec := h.do("POST", "/webhooks", `{"url": "http://localhost:9000/hook", "events": ["car.created"]}`)
+	require.Equal(t, 200, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"secret":`)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"list", "GET", "/webhooks", "", 200},
+		{"dead letters", "GET", "/webhooks/dead-letters", "", 200},
+		{"invalid url", "POST", "/webhooks", `{"url": "localhost", "events": ["car.created"]}`, 400},
+		{"unknown event", "POST", "/webhooks", `{"url": "http://localhost", "events": ["car.sold"]}`, 400},
+		{"get unknown", "GET", "/webhooks/nope", "", 404},
+		{"deliveries unknown", "GET", "/webhooks/nope/deliveries", "", 404},
+		{"delete unknown", "DELETE", "/webhooks/nope", "", 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.status, h.do(tt.method, tt.path, tt.body).Code)
+		})
+	}
+}
+
+func TestAdminDIRoutes(t *testing.T) {
+	h := newHarness(t)
+	require.Equal(t, 200, h.do("GET", "/cars/"+seededCarID, "").Code)
+
+	rec := h.do("GET", "/admin/di", "")
+	require.Equal(t, 200, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var stats []diagnostics.DefStats
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	byName := map[string]diagnostics.DefStats{}
+	for _, s := range stats {
+		byName[s.Name] = s
+	}
+	for _, name := range []string{"car-manager", "car-repository", "car-cache", "di-diagnostics"} {
+		assert.Contains(t, byName, name)
+	}
+	manager := byName["car-manager"]
+	assert.Equal(t, "request", manager.Scope)
+	assert.Equal(t, uint64(1), manager.Builds)
+	assert.Equal(t, []string{"car-cache", "car-events", "car-repository", "logger"}, manager.Dependencies)
+	assert.Equal(t, "app", byName["car-cache"].Scope)
+
+	rec = h.do("GET", "/admin/di/graph", "")
+	require.Equal(t, 200, rec.Code, rec.Body.String())
+	assert.Equal(t, "text/vnd.graphviz", rec.Header().Get("Content-Type"))
+	graph := rec.Body.String()
+	assert.True(t, strings.HasPrefix(graph, "digraph di {\n"), graph)
+	assert.True(t, strings.HasSuffix(graph, "}\n"), graph)
+	assert.Contains(t, graph, `subgraph "cluster_app"`)
+	assert.Contains(t, graph, `subgraph "cluster_request"`)
+	assert.Contains(t, graph, `"car-manager" -> "car-repository";`)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"expvar"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pwera/di/handlers"
+	"github.com/pwera/di/middlewares"
+	"github.com/sarulabs/di"
+	"go.uber.org/zap"
+)
+
+func newRouter(app di.Container, logger *zap.Logger) *mux.Router {
+	r := mux.NewRouter()
+
+	m := func(h http.HandlerFunc) http.HandlerFunc {
+		return middlewares.PanicRecoveryMiddleware(
+			di.HTTPMiddleware(h, app, func(msg string) {
+				logger.Error(msg)
+			}),
+			logger,
+		)
+	}
+
+	r.HandleFunc("/cars", m(handlers.GetCarListHandler)).Methods("GET")
+	r.HandleFunc("/cars", m(handlers.PostCarHandler)).Methods("POST")
+	r.HandleFunc("/cars/{carId}", m(handlers.GetCarHandler)).Methods("GET")
+	r.HandleFunc("/cars/{carId}", m(handlers.PutCarHandler)).Methods("PUT")
+	r.HandleFunc("/cars/{carId}", m(handlers.DeleteCarHandler)).Methods("DELETE")
+
+	r.HandleFunc("/admin/di", m(handlers.GetDIDefinitionsHandler)).Methods("GET")
+	r.HandleFunc("/admin/di/graph", m(handlers.GetDIGraphHandler)).Methods("GET")
+
+	r.HandleFunc("/webhooks", m(handlers.GetWebhookListHandler)).Methods("GET")
+	r.HandleFunc("/webhooks", m(handlers.PostWebhookHandler)).Methods("POST")
+	r.HandleFunc("/webhooks/dead-letters", m(handlers.GetWebhookDeadLettersHandler)).Methods("GET")
+	r.HandleFunc("/webhooks/{webhookId}", m(handlers.GetWebhookHandler)).Methods("GET")
+	r.HandleFunc("/webhooks/{webhookId}", m(handlers.DeleteWebhookHandler)).Methods("DELETE")
+	r.HandleFunc("/webhooks/{webhookId}/deliveries", m(handlers.GetWebhookDeliveriesHandler)).Methods("GET")
+
+	r.Handle("/debug/vars", expvar.Handler()).Methods("GET")
+
+	return r
+}
@@ -0,0 +1,240 @@
+package rpc
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+
+	"github.com/pwera/di/garage"
+	"github.com/pwera/di/helpers"
+	"github.com/pwera/di/protocol"
+	"github.com/sarulabs/di"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// CarServer serves protocol.CarService with the same car-manager
+// the REST handlers get from the DI container.
+type CarServer struct {
+	protocol.UnimplementedCarServiceServer
+	App    di.Container
+	Events *garage.EventBus
+	Logger *zap.Logger
+}
+
+// withManager runs f with a car-manager built in a request sub-container,
+// the gRPC counterpart of di.HTTPMiddleware.
+func (s *CarServer) withManager(f func(m garage.Manager) error) error {
+	ctn, err := s.App.SubContainer()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer func() {
+		if err := ctn.Delete(); err != nil {
+			s.Logger.Error(err.Error())
+		}
+	}()
+
+	manager, err := ctn.SafeGet("car-manager")
+	if err != nil {
+		s.Logger.Error(err.Error())
+		return status.Error(codes.Internal, "Internal Error")
+	}
+	return toStatus(f(manager.(garage.Manager)))
+}
+
+func (s *CarServer) GetCar(ctx context.Context, req *protocol.GetCarRequest) (*protocol.Car, error) {
+	var car *garage.Car
+	err := s.withManager(func(m garage.Manager) (err error) {
+		car, err = m.Get(req.Id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toProto(car), nil
+}
+
+func (s *CarServer) ListCars(ctx context.Context, req *protocol.ListCarsRequest) (*protocol.ListCarsResponse, error) {
+	offset, err := decodePageToken(req.PageToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+	}
+	size := int(req.PageSize)
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+
+	var cars *[]garage.Car
+	err = s.withManager(func(m garage.Manager) (err error) {
+		cars, err = m.GetAll()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	all := *cars
+	if offset > len(all) {
+		offset = len(all)
+	}
+	end := offset + size
+	if end > len(all) {
+		end = len(all)
+	}
+
+	resp := &protocol.ListCarsResponse{}
+	for i := range all[offset:end] {
+		resp.Cars = append(resp.Cars, toProto(&all[offset+i]))
+	}
+	if end < len(all) {
+		resp.NextPageToken = encodePageToken(end)
+	}
+	return resp, nil
+}
+
+func (s *CarServer) CreateCar(ctx context.Context, req *protocol.CreateCarRequest) (*protocol.Car, error) {
+	input, err := fromProto(req.Car)
+	if err != nil {
+		return nil, err
+	}
+
+	var car *garage.Car
+	err = s.withManager(func(m garage.Manager) (err error) {
+		car, err = m.Create(input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toProto(car), nil
+}
+
+// UpdateCar updates the car named by id, car.id may be left empty but can't
+// name another car.
+func (s *CarServer) UpdateCar(ctx context.Context, req *protocol.UpdateCarRequest) (*protocol.Car, error) {
+	input, err := fromProto(req.Car)
+	if err != nil {
+		return nil, err
+	}
+	id, err := primitive.ObjectIDFromHex(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+	if !input.ID.IsZero() && input.ID != id {
+		return nil, status.Error(codes.InvalidArgument, "car.id does not match id")
+	}
+	input.ID = id
+
+	var car *garage.Car
+	err = s.withManager(func(m garage.Manager) (err error) {
+		car, err = m.Update(req.Id, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toProto(car), nil
+}
+
+func (s *CarServer) DeleteCar(ctx context.Context, req *protocol.DeleteCarRequest) (*protocol.DeleteCarResponse, error) {
+	err := s.withManager(func(m garage.Manager) error {
+		return m.Delete(req.Id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.DeleteCarResponse{}, nil
+}
+
+func (s *CarServer) WatchCars(req *protocol.WatchCarsRequest, stream protocol.CarService_WatchCarsServer) error {
+	events, unsubscribe := s.Events.Subscribe(64)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e := <-events:
+			err := stream.Send(&protocol.CarEvent{
+				Type: string(e.Type),
+				Id:   e.ID,
+				Car:  toProto(e.Car),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toStatus maps the helpers errors to gRPC status codes.
+func toStatus(err error) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *helpers.ErrNotFound:
+		return status.Error(codes.NotFound, e.Error())
+	case *helpers.ErrValidation:
+		return status.Error(codes.InvalidArgument, e.Error())
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Internal, "Internal Error")
+}
+
+func toProto(car *garage.Car) *protocol.Car {
+	if car == nil {
+		return nil
+	}
+	return &protocol.Car{
+		Id:    car.ID.Hex(),
+		Brand: car.Brand,
+		Color: car.Color,
+	}
+}
+
+func fromProto(car *protocol.Car) (*garage.Car, error) {
+	if car == nil {
+		return nil, status.Error(codes.InvalidArgument, "car is required")
+	}
+	c := &garage.Car{Brand: car.Brand, Color: car.Color}
+	if car.Id != "" {
+		id, err := primitive.ObjectIDFromHex(car.Id)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid car id")
+		}
+		c.ID = id
+	}
+	return c, nil
+}
+
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(string(b))
+	if err != nil || offset < 0 {
+		return 0, status.Error(codes.InvalidArgument, "invalid page_token")
+	}
+	return offset, nil
+}
@@ -0,0 +1,173 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pwera/di/garage"
+	"github.com/pwera/di/protocol"
+	"github.com/sarulabs/di"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves a CarServer over an in-memory listener, with a
+// car-manager backed by a MemoryCarRepository.
+func newTestClient(t *testing.T) (protocol.CarServiceClient, *garage.MemoryCarRepository, *garage.EventBus) {
+	repo := garage.NewMemoryCarRepository()
+	events := garage.NewEventBus()
+	builder, err := di.NewBuilder()
+	require.NoError(t, err)
+	require.NoError(t, builder.Add(di.Def{
+		Name:  "car-manager",
+		Scope: di.Request,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return &garage.CarManager{Repo: repo, Logger: zap.NewNop(), Events: events}, nil
+		},
+	}))
+	app := builder.Build()
+
+	lis := bufconn.Listen(1 << 16)
+	server := grpc.NewServer()
+	protocol.RegisterCarServiceServer(server, &CarServer{App: app, Events: events, Logger: zap.NewNop()})
+	go server.Serve(lis)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+		assert.NoError(t, app.Delete())
+	})
+	return protocol.NewCarServiceClient(conn), repo, events
+}
+
+func TestCarServer_UpdateCar(t *testing.T) {
+	client, repo, _ := newTestClient(t)
+	ctx := context.Background()
+	car, err := client.CreateCar(ctx, &protocol.CreateCarRequest{Car: &protocol.Car{Brand: "bmw", Color: "red"}})
+	require.NoError(t, err)
+	other, err := client.CreateCar(ctx, &protocol.CreateCarRequest{Car: &protocol.Car{Brand: "audi", Color: "black"}})
+	require.NoError(t, err)
+
+	updated, err := client.UpdateCar(ctx, &protocol.UpdateCarRequest{Id: car.Id, Car: &protocol.Car{Brand: "bmw", Color: "white"}})
+	require.NoError(t, err)
+	assert.Equal(t, car.Id, updated.Id)
+	stored, err := repo.FindByID(car.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "white", stored.Color)
+
+	_, err = client.UpdateCar(ctx, &protocol.UpdateCarRequest{Id: car.Id, Car: &protocol.Car{Id: other.Id, Brand: "bmw", Color: "red"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	stored, err = repo.FindByID(other.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "audi", stored.Brand, "the other car is left alone")
+}
+
+func TestCarServer_ErrorCodes(t *testing.T) {
+	client, _, _ := newTestClient(t)
+	ctx := context.Background()
+	missing := "64777732f299590f2a62ffe7"
+
+	tests := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{"unknown car", func() error {
+			_, err := client.GetCar(ctx, &protocol.GetCarRequest{Id: missing})
+			return err
+		}, codes.NotFound},
+		{"update of an unknown car", func() error {
+			_, err := client.UpdateCar(ctx, &protocol.UpdateCarRequest{Id: missing, Car: &protocol.Car{Brand: "bmw", Color: "red"}})
+			return err
+		}, codes.NotFound},
+		{"invalid id", func() error {
+			_, err := client.UpdateCar(ctx, &protocol.UpdateCarRequest{Id: "nope", Car: &protocol.Car{Brand: "bmw", Color: "red"}})
+			return err
+		}, codes.InvalidArgument},
+		{"missing car", func() error {
+			_, err := client.CreateCar(ctx, &protocol.CreateCarRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{"invalid color", func() error {
+			_, err := client.CreateCar(ctx, &protocol.CreateCarRequest{Car: &protocol.Car{Brand: "bmw", Color: "black"}})
+			return err
+		}, codes.InvalidArgument},
+		{"invalid page token", func() error {
+			_, err := client.ListCars(ctx, &protocol.ListCarsRequest{PageToken: "!"})
+			return err
+		}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.code, status.Code(tt.call()))
+		})
+	}
+}
+
+func TestCarServer_WatchCars(t *testing.T) {
+	client, _, events := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.WatchCars(ctx, &protocol.WatchCarsRequest{})
+	require.NoError(t, err)
+	received := make(chan *protocol.CarEvent, 16)
+	go func() {
+		for {
+			e, err := stream.Recv()
+			if err != nil {
+				close(received)
+				return
+			}
+			received <- e
+		}
+	}()
+
+	// the server only subscribes once it got the call, probe until it did
+	require.Eventually(t, func() bool {
+		events.Publish(garage.CarEvent{Type: "probe"})
+		select {
+		case <-received:
+			return true
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+	next := func() *protocol.CarEvent {
+		for e := range received {
+			if e.Type != "probe" {
+				return e
+			}
+		}
+		t.Fatal("the stream ended")
+		return nil
+	}
+
+	car, err := client.CreateCar(ctx, &protocol.CreateCarRequest{Car: &protocol.Car{Brand: "bmw", Color: "red"}})
+	require.NoError(t, err)
+	_, err = client.UpdateCar(ctx, &protocol.UpdateCarRequest{Id: car.Id, Car: &protocol.Car{Brand: "bmw", Color: "white"}})
+	require.NoError(t, err)
+	_, err = client.DeleteCar(ctx, &protocol.DeleteCarRequest{Id: car.Id})
+	require.NoError(t, err)
+
+	e := next()
+	assert.Equal(t, string(garage.CarCreated), e.Type)
+	assert.Equal(t, car.Id, e.Id)
+	e = next()
+	assert.Equal(t, string(garage.CarUpdated), e.Type)
+	assert.Equal(t, "white", e.Car.Color)
+	e = next()
+	assert.Equal(t, string(garage.CarDeleted), e.Type)
+	assert.Equal(t, car.Id, e.Id)
+	assert.Nil(t, e.Car)
+}
@@ -2,8 +2,12 @@ package services
 
 import (
 	"context"
+	"os"
+	"time"
+
 	"github.com/pwera/di/garage"
 	"github.com/pwera/di/logging"
+	"github.com/pwera/di/webhooks"
 	"github.com/sarulabs/di"
 	mongo "go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -26,8 +30,7 @@ var Services = []di.Def{
 		},
 
 		Close: func(obj interface{}) error {
-			//obj.(*mongo.Client).Close()
-			return nil
+			return obj.(*mongo.Client).Disconnect(context.TODO())
 		},
 	}, {
 		Name:  "mongo",
@@ -37,7 +40,7 @@ var Services = []di.Def{
 			return &session, err
 		},
 		Close: func(obj interface{}) error {
-			//obj.(*mgo.Session).Close()
+			(*obj.(*mongo.Session)).EndSession(context.TODO())
 			return nil
 		},
 	}, {
@@ -52,14 +55,67 @@ var Services = []di.Def{
 				Client: client,
 			}, nil
 		},
+		Close: func(obj interface{}) error {
+			return obj.(*garage.CarRepository).Client.Disconnect(context.TODO())
+		},
+	},
+	{
+		Name:  "car-events",
+		Scope: di.App,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return garage.NewEventBus(), nil
+		},
+	},
+	{
+		Name:  "webhook-registry",
+		Scope: di.App,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return webhooks.NewRegistry(), nil
+		},
+	},
+	{
+		Name:  "webhook-dispatcher",
+		Scope: di.App,
+		Build: func(ctn di.Container) (interface{}, error) {
+			dispatcher := webhooks.NewDispatcher(
+				ctn.Get("webhook-registry").(*webhooks.Registry),
+				ctn.Get("logger").(*zap.Logger),
+			)
+			events, unsubscribe := ctn.Get("car-events").(*garage.EventBus).Subscribe(256)
+			go func() {
+				dispatcher.Run(events)
+				unsubscribe()
+			}()
+			return dispatcher, nil
+		},
+		Close: func(obj interface{}) error {
+			obj.(*webhooks.Dispatcher).Stop()
+			return nil
+		},
+	},
+	{
+		Name:  "car-cache",
+		Scope: di.App,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return garage.NewCarCache(garage.NewLRUCache(1024, time.Minute)), nil
+		},
 	},
 	{
 		Name:  "car-manager",
 		Scope: di.Request,
 		Build: func(ctn di.Container) (interface{}, error) {
-			return &garage.CarManager{
-				Repo:   ctn.Get("car-repository").(*garage.CarRepository),
+			manager := &garage.CarManager{
+				Repo:   ctn.Get("car-repository").(garage.Repository),
 				Logger: ctn.Get("logger").(*zap.Logger),
+				Events: ctn.Get("car-events").(*garage.EventBus),
+			}
+			// CAR_CACHE=off serves every request straight from mongo
+			if os.Getenv("CAR_CACHE") == "off" {
+				return manager, nil
+			}
+			return &garage.CachedCarManager{
+				Manager: manager,
+				Cache:   ctn.Get("car-cache").(*garage.CarCache),
 			}, nil
 		},
 	},
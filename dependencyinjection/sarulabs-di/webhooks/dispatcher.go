@@ -0,0 +1,205 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pwera/di/garage"
+	"go.uber.org/zap"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Dispatcher posts the car events to the matching subscriptions.
+// A failed delivery is retried with an exponential backoff
+// (Backoff, 2*Backoff, 4*Backoff, ... capped at MaxBackoff)
+// and ends in the dead-letter list after MaxAttempts attempts.
+// The retries end when the subscription is deleted.
+type Dispatcher struct {
+	Registry    *Registry
+	Client      *http.Client
+	Logger      *zap.Logger
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+
+	// mu keeps Dispatch from starting deliveries once Stop waits for them.
+	mu      sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+	stop    chan struct{}
+}
+
+func NewDispatcher(registry *Registry, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		Registry:    registry,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		Logger:      logger,
+		MaxAttempts: 5,
+		Backoff:     time.Second,
+		MaxBackoff:  time.Minute,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Run delivers the events read from the channel until it is closed or Stop is called.
+func (d *Dispatcher) Run(events <-chan garage.CarEvent) {
+	for {
+		select {
+		case <-d.stop:
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			d.Dispatch(e)
+		}
+	}
+}
+
+// Dispatch starts the delivery of e to every interested subscription.
+func (d *Dispatcher) Dispatch(e garage.CarEvent) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		d.Logger.Error(err.Error())
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, s := range d.Registry.matching(e.Type) {
+		if d.stopped {
+			d.cancelled(s, e, 0, "")
+			continue
+		}
+		d.wg.Add(1)
+		go func(s Subscription) {
+			defer d.wg.Done()
+			d.deliver(s, e, body)
+		}(s)
+	}
+}
+
+// Wait blocks until the started deliveries are done, retries included.
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Stop ends Run, dead-letters the deliveries waiting for a retry and waits
+// for the running ones.
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	if !d.stopped {
+		d.stopped = true
+		close(d.stop)
+	}
+	d.mu.Unlock()
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) deliver(s Subscription, e garage.CarEvent, body []byte) {
+	deliveryID := randomID(12)
+	var lastErr string
+
+	for attempt := 1; attempt <= d.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-d.stop:
+				d.cancelled(s, e, attempt-1, lastErr)
+				return
+			case <-time.After(d.backoff(attempt - 1)):
+			}
+			if !d.Registry.exists(s.ID) {
+				return
+			}
+		}
+
+		start := time.Now()
+		status, err := d.post(s, e, deliveryID, body)
+		delivery := Delivery{
+			ID:             deliveryID,
+			SubscriptionID: s.ID,
+			Event:          e.Type,
+			Attempt:        attempt,
+			StatusCode:     status,
+			Time:           start,
+			Duration:       time.Since(start),
+		}
+		if err == nil {
+			d.Registry.logDelivery(delivery)
+			return
+		}
+		lastErr = err.Error()
+		delivery.Error = lastErr
+		d.Registry.logDelivery(delivery)
+	}
+
+	d.Logger.Warn("webhook delivery failed", zap.String("subscription", s.ID), zap.String("error", lastErr))
+	d.Registry.addDeadLetter(DeadLetter{
+		SubscriptionID: s.ID,
+		Event:          e,
+		Attempts:       d.MaxAttempts,
+		LastError:      lastErr,
+		Time:           time.Now(),
+	})
+}
+
+// cancelled dead-letters the event the shutdown kept from s after attempts attempts.
+func (d *Dispatcher) cancelled(s Subscription, e garage.CarEvent, attempts int, lastErr string) {
+	d.Registry.addDeadLetter(DeadLetter{
+		SubscriptionID: s.ID,
+		Event:          e,
+		Attempts:       attempts,
+		LastError:      lastErr,
+		Cancelled:      true,
+		Time:           time.Now(),
+	})
+}
+
+func (d *Dispatcher) post(s Subscription, e garage.CarEvent, deliveryID string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(e.Type))
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(SignatureHeader, Sign(s.Secret, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) backoff(retry int) time.Duration {
+	delay := d.Backoff << uint(retry-1)
+	if delay <= 0 || (d.MaxBackoff > 0 && delay > d.MaxBackoff) {
+		return d.MaxBackoff
+	}
+	return delay
+}
+
+// Sign returns the value of the signature header for body,
+// receivers compare it with their own HMAC-SHA256 of the raw body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,211 @@
+package webhooks
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pwera/di/garage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestDispatcher(registry *Registry) *Dispatcher {
+	d := NewDispatcher(registry, zap.NewNop())
+	d.MaxAttempts = 3
+	d.Backoff = time.Millisecond
+	d.MaxBackoff = 5 * time.Millisecond
+	return d
+}
+
+func TestDispatcher_SignsPayload(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer receiver.Close()
+
+	registry := NewRegistry()
+	sub, err := registry.Add(&Subscription{URL: receiver.URL, Events: []garage.CarEventType{garage.CarCreated}, Secret: "s3cr3t"})
+	require.NoError(t, err)
+
+	d := newTestDispatcher(registry)
+	d.Dispatch(garage.CarEvent{Type: garage.CarCreated, ID: "1", Car: &garage.Car{Brand: "bmw", Color: "red"}})
+	d.Wait()
+
+	r := <-received
+	body := <-bodies
+	assert.Equal(t, "car.created", r.Header.Get(EventHeader))
+	assert.Equal(t, Sign("s3cr3t", body), r.Header.Get(SignatureHeader))
+	assert.Contains(t, string(body), `"brand":"bmw"`)
+
+	deliveries, err := registry.Deliveries(sub.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, 200, deliveries[0].StatusCode)
+	assert.Empty(t, registry.DeadLetters())
+}
+
+func TestDispatcher_SkipsOtherEvents(t *testing.T) {
+	var calls int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer receiver.Close()
+
+	registry := NewRegistry()
+	_, err := registry.Add(&Subscription{URL: receiver.URL, Events: []garage.CarEventType{garage.CarDeleted}})
+	require.NoError(t, err)
+
+	d := newTestDispatcher(registry)
+	d.Dispatch(garage.CarEvent{Type: garage.CarCreated, ID: "1"})
+	d.Wait()
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestDispatcher_RetriesUntilSuccess(t *testing.T) {
+	var calls int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	registry := NewRegistry()
+	sub, err := registry.Add(&Subscription{URL: receiver.URL, Events: []garage.CarEventType{garage.CarUpdated}})
+	require.NoError(t, err)
+
+	d := newTestDispatcher(registry)
+	d.Dispatch(garage.CarEvent{Type: garage.CarUpdated, ID: "1"})
+	d.Wait()
+
+	deliveries, err := registry.Deliveries(sub.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	assert.Equal(t, 503, deliveries[0].StatusCode)
+	assert.Equal(t, 503, deliveries[1].StatusCode)
+	assert.Equal(t, 200, deliveries[2].StatusCode)
+	assert.Equal(t, 3, deliveries[2].Attempt)
+	assert.Empty(t, registry.DeadLetters())
+}
+
+func TestDispatcher_DeadLetter(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	registry := NewRegistry()
+	sub, err := registry.Add(&Subscription{URL: receiver.URL, Events: []garage.CarEventType{garage.CarDeleted}})
+	require.NoError(t, err)
+
+	d := newTestDispatcher(registry)
+	d.Dispatch(garage.CarEvent{Type: garage.CarDeleted, ID: "42"})
+	d.Wait()
+
+	dead := registry.DeadLetters()
+	require.Len(t, dead, 1)
+	assert.Equal(t, sub.ID, dead[0].SubscriptionID)
+	assert.Equal(t, "42", dead[0].Event.ID)
+	assert.Equal(t, 3, dead[0].Attempts)
+	assert.Equal(t, "unexpected status 500", dead[0].LastError)
+}
+
+func TestRegistry_Validation(t *testing.T) {
+	registry := NewRegistry()
+
+	_, err := registry.Add(&Subscription{URL: "not a url", Events: []garage.CarEventType{garage.CarCreated}})
+	assert.Error(t, err)
+
+	_, err = registry.Add(&Subscription{URL: "http://localhost/hook", Events: []garage.CarEventType{"car.painted"}})
+	assert.Error(t, err)
+
+	sub, err := registry.Add(&Subscription{URL: "http://localhost/hook", Events: []garage.CarEventType{garage.CarCreated}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, sub.Secret)
+
+	stored, err := registry.Get(sub.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Secret)
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 2*time.Second, d.backoff(2))
+	assert.Equal(t, 4*time.Second, d.backoff(3))
+	assert.Equal(t, 5*time.Second, d.backoff(4))
+}
+
+func TestDispatcher_StopDeadLettersPendingRetries(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	registry := NewRegistry()
+	sub, err := registry.Add(&Subscription{URL: receiver.URL, Events: []garage.CarEventType{garage.CarUpdated}})
+	require.NoError(t, err)
+
+	d := newTestDispatcher(registry)
+	d.Backoff, d.MaxBackoff = time.Hour, time.Hour
+	d.Dispatch(garage.CarEvent{Type: garage.CarUpdated, ID: "1"})
+	require.Eventually(t, func() bool {
+		deliveries, _ := registry.Deliveries(sub.ID)
+		return len(deliveries) == 1
+	}, time.Second, time.Millisecond)
+	d.Stop()
+	d.Dispatch(garage.CarEvent{Type: garage.CarUpdated, ID: "2"})
+
+	dead := registry.DeadLetters()
+	require.Len(t, dead, 2)
+	assert.Equal(t, "1", dead[0].Event.ID)
+	assert.Equal(t, 1, dead[0].Attempts)
+	assert.Equal(t, "unexpected status 502", dead[0].LastError)
+	assert.True(t, dead[0].Cancelled)
+	assert.Equal(t, "2", dead[1].Event.ID)
+	assert.Equal(t, 0, dead[1].Attempts)
+	assert.True(t, dead[1].Cancelled)
+}
+
+func TestDispatcher_StopsRetryingDeletedSubscriptions(t *testing.T) {
+	var calls int32
+	registry := NewRegistry()
+	var sub *Subscription
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		registry.Delete(sub.ID)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+	sub, err := registry.Add(&Subscription{URL: receiver.URL, Events: []garage.CarEventType{garage.CarDeleted}})
+	require.NoError(t, err)
+
+	d := newTestDispatcher(registry)
+	d.Dispatch(garage.CarEvent{Type: garage.CarDeleted, ID: "42"})
+	d.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Empty(t, registry.DeadLetters())
+}
+
+func TestRegistry_DeadLettersAreCapped(t *testing.T) {
+	registry := NewRegistry()
+	for i := 0; i < maxDeadLetters+10; i++ {
+		registry.addDeadLetter(DeadLetter{Attempts: i})
+	}
+
+	dead := registry.DeadLetters()
+	require.Len(t, dead, maxDeadLetters)
+	assert.Equal(t, 10, dead[0].Attempts)
+	assert.Equal(t, maxDeadLetters+9, dead[len(dead)-1].Attempts)
+}
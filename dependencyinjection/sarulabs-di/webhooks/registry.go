@@ -0,0 +1,224 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pwera/di/garage"
+	"github.com/pwera/di/helpers"
+)
+
+const (
+	maxDeliveriesPerSubscription = 100
+	maxDeadLetters               = 1000
+)
+
+var knownEvents = map[garage.CarEventType]bool{
+	garage.CarCreated: true,
+	garage.CarUpdated: true,
+	garage.CarDeleted: true,
+}
+
+// Subscription is a webhook URL registered for some car events.
+// Secret is the HMAC-SHA256 key used to sign the payloads,
+// it is only returned when the subscription is created.
+type Subscription struct {
+	ID        string                `json:"id"`
+	URL       string                `json:"url"`
+	Events    []garage.CarEventType `json:"events"`
+	Secret    string                `json:"secret,omitempty"`
+	CreatedAt time.Time             `json:"createdAt"`
+}
+
+func (s *Subscription) wants(t garage.CarEventType) bool {
+	for _, e := range s.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is one attempt to post an event to a subscription.
+type Delivery struct {
+	ID             string              `json:"id"`
+	SubscriptionID string              `json:"subscriptionId"`
+	Event          garage.CarEventType `json:"event"`
+	Attempt        int                 `json:"attempt"`
+	StatusCode     int                 `json:"statusCode,omitempty"`
+	Error          string              `json:"error,omitempty"`
+	Time           time.Time           `json:"time"`
+	Duration       time.Duration       `json:"duration"`
+}
+
+// DeadLetter is an event that could not be delivered after all the retries,
+// or whose delivery was cancelled by the shutdown of the dispatcher.
+type DeadLetter struct {
+	SubscriptionID string          `json:"subscriptionId"`
+	Event          garage.CarEvent `json:"event"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"lastError"`
+	Cancelled      bool            `json:"cancelled,omitempty"`
+	Time           time.Time       `json:"time"`
+}
+
+// Registry keeps the subscriptions, their delivery logs and the dead letters in memory.
+type Registry struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*Subscription
+	deliveries    map[string][]Delivery
+	deadLetters   []DeadLetter
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		subscriptions: map[string]*Subscription{},
+		deliveries:    map[string][]Delivery{},
+	}
+}
+
+// Add validates and stores a new subscription.
+// A secret is generated when none is given.
+func (r *Registry) Add(s *Subscription) (*Subscription, error) {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, helpers.NewErrValidation("`url` must be an absolute http(s) URL")
+	}
+	if len(s.Events) == 0 {
+		return nil, helpers.NewErrValidation("`events` can not be empty")
+	}
+	for _, e := range s.Events {
+		if !knownEvents[e] {
+			return nil, helpers.NewErrValidation("Event `" + string(e) + "` does not exist. Available events: car.created, car.updated, car.deleted")
+		}
+	}
+	if s.Secret == "" {
+		s.Secret = randomID(32)
+	}
+	s.ID = randomID(12)
+	s.CreatedAt = time.Now()
+
+	r.mu.Lock()
+	r.subscriptions[s.ID] = s
+	r.mu.Unlock()
+
+	created := *s
+	return &created, nil
+}
+
+func (r *Registry) Get(id string) (*Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.subscriptions[id]
+	if !ok {
+		return nil, helpers.NewErrNotFound("Webhook " + id + " does not exist")
+	}
+	return public(s), nil
+}
+
+func (r *Registry) All() []*Subscription {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subs := make([]*Subscription, 0, len(r.subscriptions))
+	for _, s := range r.subscriptions {
+		subs = append(subs, public(s))
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+	return subs
+}
+
+func (r *Registry) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[id]; !ok {
+		return helpers.NewErrNotFound("Webhook " + id + " does not exist")
+	}
+	delete(r.subscriptions, id)
+	delete(r.deliveries, id)
+	return nil
+}
+
+func (r *Registry) Deliveries(id string) ([]Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.subscriptions[id]; !ok {
+		return nil, helpers.NewErrNotFound("Webhook " + id + " does not exist")
+	}
+	return append([]Delivery{}, r.deliveries[id]...), nil
+}
+
+func (r *Registry) DeadLetters() []DeadLetter {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]DeadLetter{}, r.deadLetters...)
+}
+
+// matching returns the subscriptions, secrets included, interested in t.
+func (r *Registry) matching(t garage.CarEventType) []Subscription {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subs []Subscription
+	for _, s := range r.subscriptions {
+		if s.wants(t) {
+			subs = append(subs, *s)
+		}
+	}
+	return subs
+}
+
+func (r *Registry) logDelivery(d Delivery) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[d.SubscriptionID]; !ok {
+		return
+	}
+	log := append(r.deliveries[d.SubscriptionID], d)
+	if len(log) > maxDeliveriesPerSubscription {
+		log = log[len(log)-maxDeliveriesPerSubscription:]
+	}
+	r.deliveries[d.SubscriptionID] = log
+}
+
+// exists tells whether the subscription was not deleted.
+func (r *Registry) exists(id string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.subscriptions[id]
+	return ok
+}
+
+// addDeadLetter keeps the last maxDeadLetters dead letters.
+func (r *Registry) addDeadLetter(d DeadLetter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dead := append(r.deadLetters, d)
+	if len(dead) > maxDeadLetters {
+		dead = dead[len(dead)-maxDeadLetters:]
+	}
+	r.deadLetters = dead
+}
+
+func public(s *Subscription) *Subscription {
+	p := *s
+	p.Secret = ""
+	return &p
+}
+
+func randomID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,67 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type ActivityService struct {
+	EventRepository domain.EventRepository
+	IssueRepository domain.IssueRepository
+}
+
+// Record appends the published events that aren't stored yet to the event store,
+// the IssueRepository stores the changes of the issues with their events.
+// It is subscribed to the EventBus before the other handlers.
+func (as ActivityService) Record(e domain.IssueEvent) error {
+	if e.Id != 0 {
+		return nil
+	}
+	return as.EventRepository.Append(&e)
+}
+
+// Activity returns the events of the issue, the oldest first.
+func (as ActivityService) Activity(issueId int64) ([]*domain.IssueEvent, error) {
+	events, err := as.EventRepository.ForIssue(issueId)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		if _, err := as.IssueRepository.GetById(issueId); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+func (as ActivityService) Since(afterId int64, limit int) ([]*domain.IssueEvent, error) {
+	return as.EventRepository.Since(afterId, limit)
+}
+
+func (as ActivityService) LastId() (int64, error) {
+	return as.EventRepository.LastId()
+}
+
+// Check replays the events of the issue and compares the result with the stored issue.
+func (as ActivityService) Check(issueId int64) (*domain.IssueConsistency, error) {
+	stored, err := as.IssueRepository.GetById(issueId)
+	if err != nil {
+		return nil, err
+	}
+	events, err := as.EventRepository.ForIssue(issueId)
+	if err != nil {
+		return nil, err
+	}
+	replayed, err := domain.ReplayIssue(events)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.NewValidationError(fmt.Sprintf("issue %d has no recorded creation", issueId))
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &domain.IssueConsistency{Stored: stored, Replayed: replayed}
+	c.Compare()
+	return c, nil
+}
@@ -0,0 +1,213 @@
+package application
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+)
+
+const DefaultAttachmentMaxSize = 10 << 20
+
+// DefaultAttachmentTypes are the content types accepted when none are configured,
+// a type may end with /* to accept all its sub types.
+var DefaultAttachmentTypes = []string{"image/*", "text/plain", "application/pdf", "application/zip"}
+
+type AttachmentService struct {
+	AttachmentRepository domain.AttachmentRepository
+	IssueRepository      domain.IssueRepository
+	BlobStore            domain.BlobStore
+	MaxSize              int64
+	AllowedTypes         []string
+}
+
+func (as AttachmentService) Attachments(issueId int64) ([]*domain.Attachment, error) {
+	if _, err := as.IssueRepository.GetById(issueId); err != nil {
+		return nil, err
+	}
+	return as.AttachmentRepository.ForIssue(issueId)
+}
+
+// Upload trusts the content and not the name of the file for its content type.
+func (as AttachmentService) Upload(issueId int64, name string, r io.Reader) (*domain.Attachment, error) {
+	if _, err := as.IssueRepository.GetById(issueId); err != nil {
+		return nil, err
+	}
+	name = filepath.Base(strings.TrimSpace(name))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return nil, domain.NewValidationError("attachment file name is required")
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+	if !as.allowed(contentType) {
+		return nil, fmt.Errorf("%s: %w", contentType, domain.ErrUnsupportedType)
+	}
+
+	content := &sizeLimit{r: io.MultiReader(bytes.NewReader(head), r), left: as.MaxSize, max: as.MaxSize}
+	digest, size, err := as.BlobStore.Put(content)
+	if err != nil {
+		return nil, err
+	}
+	a := &domain.Attachment{
+		IssueId:     issueId,
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+		Digest:      digest,
+		CreatedAt:   time.Now().UTC(),
+	}
+	unlock := digests.Lock(digest)
+	err = as.AttachmentRepository.Create(a)
+	as.BlobStore.Unhold(digest)
+	unlock()
+	if err != nil {
+		as.release(digest)
+		return nil, err
+	}
+	return a, nil
+}
+
+// Open returns the content of the attachment, the caller closes it.
+func (as AttachmentService) Open(issueId, id int64) (*domain.Attachment, io.ReadSeekCloser, error) {
+	a, err := as.attachment(issueId, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := as.BlobStore.Open(a.Digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, content, nil
+}
+
+func (as AttachmentService) Delete(issueId, id int64) error {
+	a, err := as.attachment(issueId, id)
+	if err != nil {
+		return err
+	}
+	if err := as.AttachmentRepository.Delete(id); err != nil {
+		return err
+	}
+	return as.release(a.Digest)
+}
+
+// RemoveUnused deletes the contents no attachment uses anymore, like those of
+// the attachments a migration removed with their issue.
+func (as AttachmentService) RemoveUnused() error {
+	stored, err := as.BlobStore.Digests()
+	if err != nil {
+		return err
+	}
+	for _, digest := range stored {
+		if err := as.release(digest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (as AttachmentService) release(digest string) error {
+	return releaseContent(as.AttachmentRepository, as.BlobStore, digest)
+}
+
+// releaseContent drops the content once no attachment uses it anymore.
+func releaseContent(repo domain.AttachmentRepository, store domain.BlobStore, digest string) error {
+	defer digests.Lock(digest)()
+	n, err := repo.CountDigest(digest)
+	if err != nil || n > 0 {
+		return err
+	}
+	return store.Delete(digest)
+}
+
+// digests serializes counting the attachments of a content before deleting it
+// with the uploads adding an attachment of the same content.
+var digests = &keyedMutex{locks: map[string]*keyedLock{}}
+
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	waiting int
+}
+
+// Lock locks key and returns the function unlocking it.
+func (m *keyedMutex) Lock(key string) func() {
+	m.mu.Lock()
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		m.locks[key] = l
+	}
+	l.waiting++
+	m.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		m.mu.Lock()
+		if l.waiting--; l.waiting == 0 {
+			delete(m.locks, key)
+		}
+		m.mu.Unlock()
+	}
+}
+
+func (as AttachmentService) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range as.AllowedTypes {
+		if allowed == mediaType {
+			return true
+		}
+		if prefix := strings.TrimSuffix(allowed, "*"); prefix != allowed && strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (as AttachmentService) attachment(issueId, id int64) (*domain.Attachment, error) {
+	a, err := as.AttachmentRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if a.IssueId != issueId {
+		return nil, domain.ErrNotFound
+	}
+	return a, nil
+}
+
+// sizeLimit fails with domain.ErrTooLarge as soon as more than max bytes were read.
+type sizeLimit struct {
+	r    io.Reader
+	left int64
+	max  int64
+}
+
+func (l *sizeLimit) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n, fmt.Errorf("attachment is larger than %d bytes: %w", l.max, domain.ErrTooLarge)
+	}
+	return n, err
+}
@@ -0,0 +1,157 @@
+package application
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pwera/ddd/domain"
+	"github.com/pwera/ddd/persistence/blob"
+	"github.com/pwera/ddd/persistence/db"
+)
+
+func TestAttachmentService_ContentOutlivesItsLastAttachment(t *testing.T) {
+	conn, err := db.Open(db.DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	store, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	projects := db.NewProjectRepository(conn)
+	attachments := AttachmentService{
+		AttachmentRepository: db.NewAttachmentRepository(conn),
+		IssueRepository:      db.NewIssueRepository(conn),
+		BlobStore:            store,
+		MaxSize:              DefaultAttachmentMaxSize,
+		AllowedTypes:         DefaultAttachmentTypes,
+	}
+	issues := IssueService{
+		IssueRepository:      attachments.IssueRepository,
+		ProjectRepository:    projects,
+		WorkflowRepository:   db.NewWorkflowRepository(conn),
+		AttachmentRepository: attachments.AttachmentRepository,
+		BlobStore:            store,
+	}
+	p := &domain.Project{Name: "Tracker"}
+	if err := projects.Create(p); err != nil {
+		t.Fatal(err)
+	}
+	first, second := &domain.Issue{Title: "First", ProjectId: p.Id, Priority: domain.PriorityLow}, &domain.Issue{Title: "Second", ProjectId: p.Id, Priority: domain.PriorityLow}
+	for _, i := range []*domain.Issue{first, second} {
+		if err := issues.Create(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// uploads and deletions of the same content racing each other never leave
+	// an attachment without its content
+	var wg sync.WaitGroup
+	for n := 0; n < 20; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a, err := attachments.Upload(first.Id, "notes.txt", strings.NewReader("shared notes"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if err := attachments.Delete(first.Id, a.Id); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	kept, err := attachments.Upload(second.Id, "notes.txt", strings.NewReader("shared notes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	if _, content, err := attachments.Open(second.Id, kept.Id); err != nil {
+		t.Fatalf("expected the content of the kept attachment but got %v", err)
+	} else {
+		content.Close()
+	}
+
+	if _, err := attachments.Upload(first.Id, "notes.txt", strings.NewReader("shared notes")); err != nil {
+		t.Fatal(err)
+	}
+	if err := issues.Delete(first.Id); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := store.Open(kept.Digest); err != nil {
+		t.Fatalf("expected the content still used by the second issue but got %v", err)
+	} else {
+		content.Close()
+	}
+	if err := issues.Delete(second.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Open(kept.Digest); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected the content to go with its last attachment but got %v", err)
+	}
+}
+
+func TestAttachmentService_RemoveUnused(t *testing.T) {
+	conn, err := db.Open(db.DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	store, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	projects := db.NewProjectRepository(conn)
+	attachments := AttachmentService{
+		AttachmentRepository: db.NewAttachmentRepository(conn),
+		IssueRepository:      db.NewIssueRepository(conn),
+		BlobStore:            store,
+		MaxSize:              DefaultAttachmentMaxSize,
+		AllowedTypes:         DefaultAttachmentTypes,
+	}
+	p := &domain.Project{Name: "Tracker"}
+	if err := projects.Create(p); err != nil {
+		t.Fatal(err)
+	}
+	issues := IssueService{
+		IssueRepository:    attachments.IssueRepository,
+		ProjectRepository:  projects,
+		WorkflowRepository: db.NewWorkflowRepository(conn),
+	}
+	issue := &domain.Issue{Title: "Bug", ProjectId: p.Id, Priority: domain.PriorityLow}
+	if err := issues.Create(issue); err != nil {
+		t.Fatal(err)
+	}
+	used, err := attachments.Upload(issue.Id, "notes.txt", strings.NewReader("used notes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// left behind by an attachment removed without releasing its content
+	unused, _, err := store.Put(strings.NewReader("unused notes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Unhold(unused)
+	// still held by an upload in progress
+	uploading, _, err := store.Put(strings.NewReader("uploading notes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := attachments.RemoveUnused(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Open(unused); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected the unused content to be removed but got %v", err)
+	}
+	for _, digest := range []string{used.Digest, uploading} {
+		content, err := store.Open(digest)
+		if err != nil {
+			t.Fatalf("expected content %s to be kept but got %v", digest, err)
+		}
+		content.Close()
+	}
+}
@@ -0,0 +1,187 @@
+package application
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/net/context"
+)
+
+const (
+	DefaultAccessTTL  = 15 * time.Minute
+	DefaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+// noPassword is a bcrypt hash compared when the email is unknown, so that a
+// login takes as long for unknown users as for a wrong password.
+var noPassword = []byte("$2a$10$CQZXgkTMeIQsgZBh8Z5JCOP8AEQ3vv4FS/IiqHl54rljiFU5fWA/K")
+
+var errBadCredentials = fmt.Errorf("invalid email or password: %w", domain.ErrUnauthorized)
+
+type AuthService struct {
+	UserRepository  domain.UserRepository
+	TokenRepository domain.TokenRepository
+	Tokens          domain.TokenIssuer
+	AccessTTL       time.Duration
+	RefreshTTL      time.Duration
+}
+
+func (as AuthService) Login(email, password string) (*domain.Tokens, error) {
+	u, err := as.UserRepository.ByEmail(email)
+	if errors.Is(err, domain.ErrNotFound) {
+		bcrypt.CompareHashAndPassword(noPassword, []byte(password))
+		return nil, errBadCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.PasswordHash == "" || bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+		return nil, errBadCredentials
+	}
+	family, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	return as.issue(u.Id, family)
+}
+
+// Refresh hands out new tokens for a refresh token used for the first time. A
+// second use means the token leaked, every token of its login is revoked then.
+func (as AuthService) Refresh(refreshToken string) (*domain.Tokens, error) {
+	now := time.Now().UTC()
+	hash := hashToken(refreshToken)
+	t, err := as.TokenRepository.RefreshToken(hash)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("unknown refresh token: %w", domain.ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t.Revoked || !now.Before(t.ExpiresAt) {
+		return nil, fmt.Errorf("refresh token expired or revoked: %w", domain.ErrUnauthorized)
+	}
+	first, err := as.TokenRepository.UseRefreshToken(hash, now)
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		if err := as.TokenRepository.RevokeFamily(t.Family); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("refresh token was already used: %w", domain.ErrUnauthorized)
+	}
+	return as.issue(t.UserId, t.Family)
+}
+
+// Logout revokes the refresh tokens of the login, found with either token, and
+// the access token.
+func (as AuthService) Logout(accessToken, refreshToken string) error {
+	if accessToken == "" && refreshToken == "" {
+		return domain.NewValidationError("an access or a refresh token is required")
+	}
+	if refreshToken != "" {
+		t, err := as.TokenRepository.RefreshToken(hashToken(refreshToken))
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("unknown refresh token: %w", domain.ErrUnauthorized)
+		}
+		if err != nil {
+			return err
+		}
+		if err := as.TokenRepository.RevokeFamily(t.Family); err != nil {
+			return err
+		}
+	}
+	if accessToken != "" {
+		access, err := as.Tokens.Parse(accessToken)
+		if err != nil {
+			return err
+		}
+		if access.Family != "" {
+			if err := as.TokenRepository.RevokeFamily(access.Family); err != nil {
+				return err
+			}
+		}
+		return as.TokenRepository.Revoke(&domain.RevokedToken{Id: access.Id, ExpiresAt: access.ExpiresAt})
+	}
+	return nil
+}
+
+func (as AuthService) Revoked() ([]*domain.RevokedToken, error) {
+	return as.TokenRepository.Revoked(time.Now().UTC())
+}
+
+// Verify serves the servers sharing the token repository, the others verify
+// the tokens with the published keys and revocations.
+func (as AuthService) Verify(accessToken string) (*domain.AccessToken, error) {
+	access, err := as.Tokens.Parse(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := as.TokenRepository.IsRevoked(access.Id, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fmt.Errorf("access token was revoked: %w", domain.ErrUnauthorized)
+	}
+	return access, nil
+}
+
+// PruneEvery forgets the expired revoked tokens until ctx is done.
+func (as AuthService) PruneEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := as.TokenRepository.PruneRevoked(time.Now().UTC()); err != nil {
+			log.Printf("fail to prune the revoked tokens: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (as AuthService) issue(userId int64, family string) (*domain.Tokens, error) {
+	access, claims, err := as.Tokens.Issue(userId, family, as.AccessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	err = as.TokenRepository.CreateRefreshToken(&domain.RefreshToken{
+		Hash:      hashToken(refresh),
+		UserId:    userId,
+		Family:    family,
+		ExpiresAt: time.Now().UTC().Add(as.RefreshTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Tokens{AccessToken: access, ExpiresAt: claims.ExpiresAt, RefreshToken: refresh}, nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is how refresh tokens are stored, a leaked database doesn't leak usable tokens.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
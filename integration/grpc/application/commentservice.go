@@ -0,0 +1,159 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	DefaultCommentLimit = 20
+	MaxCommentLimit     = 100
+)
+
+type CommentService struct {
+	CommentRepository     domain.CommentRepository
+	IssueRepository       domain.IssueRepository
+	UserRepository        domain.UserRepository
+	ParticipantRepository domain.ParticipantRepository
+	Events                domain.EventPublisher
+}
+
+// Comments returns a page of the comments of the issue in the order they were written,
+// replies point to their parent through ParentId.
+func (cs CommentService) Comments(issueId int64, limit, offset int) (*domain.CommentPage, error) {
+	if _, err := cs.IssueRepository.GetById(issueId); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultCommentLimit
+	}
+	if limit > MaxCommentLimit {
+		limit = MaxCommentLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	comments, err := cs.CommentRepository.ForIssue(issueId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	total, err := cs.CommentRepository.Count(issueId)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CommentPage{Comments: comments, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Create stores the Markdown body as it was sent with its event and makes the
+// author watch the issue.
+func (cs CommentService) Create(c *domain.Comment) error {
+	if _, err := cs.IssueRepository.GetById(c.IssueId); err != nil {
+		return err
+	}
+	if strings.TrimSpace(c.Body) == "" {
+		return domain.NewValidationError("comment body is required")
+	}
+	if _, err := cs.UserRepository.User(c.AuthorId); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.NewValidationError(fmt.Sprintf("user %d does not exist", c.AuthorId))
+		}
+		return err
+	}
+	if c.ParentId != 0 {
+		parent, err := cs.CommentRepository.GetById(c.ParentId)
+		if errors.Is(err, domain.ErrNotFound) || (err == nil && parent.IssueId != c.IssueId) {
+			return domain.NewValidationError(fmt.Sprintf("comment %d is not on issue %d", c.ParentId, c.IssueId))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	c.Deleted = false
+	c.CreatedAt = time.Now().UTC()
+	c.UpdatedAt = c.CreatedAt
+	commented := &domain.IssueEvent{
+		Type:    domain.EventIssueCommented,
+		IssueId: c.IssueId,
+		ActorId: c.AuthorId,
+		Message: fmt.Sprintf("user %d commented on issue %d", c.AuthorId, c.IssueId),
+		At:      c.CreatedAt,
+	}
+	if err := cs.CommentRepository.Create(c, commented); err != nil {
+		return err
+	}
+	if err := cs.ParticipantRepository.Watch(c.IssueId, c.AuthorId); err != nil {
+		return err
+	}
+	publish(cs.Events, *commented)
+	return nil
+}
+
+func (cs CommentService) Edit(issueId, id int64, body string) (*domain.Comment, error) {
+	c, err := cs.comment(issueId, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Deleted {
+		return nil, domain.NewValidationError("a deleted comment can't be edited")
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, domain.NewValidationError("comment body is required")
+	}
+	if body == c.Body {
+		return c, nil
+	}
+	c.Body = body
+	c.UpdatedAt = time.Now().UTC()
+	edited := &domain.IssueEvent{
+		Type:    domain.EventCommentEdited,
+		IssueId: issueId,
+		Message: fmt.Sprintf("comment %d on issue %d was edited", id, issueId),
+		At:      c.UpdatedAt,
+	}
+	if err := cs.CommentRepository.Update(c, edited); err != nil {
+		return nil, err
+	}
+	publish(cs.Events, *edited)
+	return c, nil
+}
+
+// Delete blanks the comment so that the replies to it stay in the thread.
+func (cs CommentService) Delete(issueId, id int64) error {
+	if _, err := cs.comment(issueId, id); err != nil {
+		return err
+	}
+	deleted := &domain.IssueEvent{
+		Type:    domain.EventCommentDeleted,
+		IssueId: issueId,
+		Message: fmt.Sprintf("comment %d on issue %d was deleted", id, issueId),
+		At:      time.Now().UTC(),
+	}
+	if err := cs.CommentRepository.Delete(id, deleted); err != nil {
+		return err
+	}
+	publish(cs.Events, *deleted)
+	return nil
+}
+
+func (cs CommentService) Revisions(issueId, id int64) ([]*domain.CommentRevision, error) {
+	if _, err := cs.comment(issueId, id); err != nil {
+		return nil, err
+	}
+	return cs.CommentRepository.Revisions(id)
+}
+
+// comment loads a comment and hides the ones that belong to another issue.
+func (cs CommentService) comment(issueId, id int64) (*domain.Comment, error) {
+	c, err := cs.CommentRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if c.IssueId != issueId {
+		return nil, domain.ErrNotFound
+	}
+	return c, nil
+}
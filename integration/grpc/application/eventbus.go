@@ -0,0 +1,43 @@
+package application
+
+import (
+	"log"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+)
+
+// EventBus hands every published event to the subscribed handlers in order,
+// a failing handler doesn't keep the event from the next ones.
+type EventBus struct {
+	handlers []domain.EventHandler
+}
+
+func (b *EventBus) Subscribe(h domain.EventHandler) {
+	b.handlers = append(b.handlers, h)
+}
+
+// Publish returns the error of the first failing handler.
+func (b *EventBus) Publish(e domain.IssueEvent) error {
+	var first error
+	for _, h := range b.handlers {
+		if err := h(e); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// publish stamps the events without a time, services without a publisher drop them.
+// The change is stored by then, so a failing handler is only logged.
+func publish(p domain.EventPublisher, e domain.IssueEvent) {
+	if p == nil {
+		return
+	}
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+	if err := p.Publish(e); err != nil {
+		log.Printf("fail to handle %s of issue %d: %v", e.Type, e.IssueId, err)
+	}
+}
@@ -0,0 +1,124 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type FieldService struct {
+	FieldRepository   domain.FieldRepository
+	ProjectRepository domain.ProjectRepository
+	IssueRepository   domain.IssueRepository
+	UserRepository    domain.UserRepository
+}
+
+func (fs FieldService) Fields(projectId int64) ([]*domain.FieldDefinition, error) {
+	if _, err := fs.ProjectRepository.GetById(projectId); err != nil {
+		return nil, err
+	}
+	return fs.FieldRepository.ForProject(projectId)
+}
+
+func (fs FieldService) Define(d *domain.FieldDefinition) error {
+	if _, err := fs.ProjectRepository.GetById(d.ProjectId); err != nil {
+		return err
+	}
+	d.Name = strings.TrimSpace(d.Name)
+	if d.Name == "" {
+		return domain.NewValidationError("field name is required")
+	}
+	if !d.Type.IsValid() {
+		return domain.NewValidationError(fmt.Sprintf("field type must be one of %v", domain.FieldTypes))
+	}
+	if d.Type == domain.FieldEnum && len(d.Options) == 0 {
+		return domain.NewValidationError("an enum field needs options")
+	}
+	if d.Type != domain.FieldEnum {
+		d.Options = nil
+	}
+	return fs.FieldRepository.Create(d)
+}
+
+func (fs FieldService) Delete(projectId, id int64) error {
+	d, err := fs.FieldRepository.GetById(id)
+	if err != nil {
+		return err
+	}
+	if d.ProjectId != projectId {
+		return domain.ErrNotFound
+	}
+	return fs.FieldRepository.Delete(id)
+}
+
+// Set validates every value before storing them together.
+func (fs FieldService) Set(issueId int64, values map[string]interface{}) (map[string]string, error) {
+	issue, err := fs.IssueRepository.GetById(issueId)
+	if err != nil {
+		return nil, err
+	}
+	definitions, err := fs.FieldRepository.ForProject(issue.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*domain.FieldDefinition, len(definitions))
+	for _, d := range definitions {
+		byName[d.Name] = d
+	}
+
+	var set []*domain.FieldValue
+	var unset []int64
+	for name, value := range values {
+		d, ok := byName[name]
+		if !ok {
+			return nil, domain.NewValidationError(fmt.Sprintf("project %d has no field %q", issue.ProjectId, name))
+		}
+		if value == nil {
+			unset = append(unset, d.Id)
+			continue
+		}
+		normalized, err := d.Normalize(value)
+		if err != nil {
+			return nil, err
+		}
+		if d.Type == domain.FieldUser {
+			if err := fs.checkUser(normalized); err != nil {
+				return nil, err
+			}
+		}
+		set = append(set, &domain.FieldValue{IssueId: issueId, FieldId: d.Id, Value: normalized})
+	}
+
+	if err := fs.FieldRepository.SetValues(issueId, set, unset); err != nil {
+		return nil, err
+	}
+	return issueFields(fs.FieldRepository, issueId)
+}
+
+func (fs FieldService) checkUser(id string) error {
+	userId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return err
+	}
+	_, err = fs.UserRepository.User(userId)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.NewValidationError(fmt.Sprintf("user %s does not exist", id))
+	}
+	return err
+}
+
+// issueFields returns the custom fields of an issue by name.
+func issueFields(repo domain.FieldRepository, issueId int64) (map[string]string, error) {
+	values, err := repo.Values(issueId)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]string, len(values))
+	for _, v := range values {
+		fields[v.Name] = v.Value
+	}
+	return fields, nil
+}
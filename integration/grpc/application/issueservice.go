@@ -1,14 +1,305 @@
 package application
 
-import "github.com/pwera/ddd/domain"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+)
 
 type IssueService struct {
-	IssueRepository domain.IssueRepository
+	IssueRepository       domain.IssueRepository
+	ProjectRepository     domain.ProjectRepository
+	WorkflowRepository    domain.WorkflowRepository
+	HistoryRepository     domain.HistoryRepository
+	LabelRepository       domain.LabelRepository
+	FieldRepository       domain.FieldRepository
+	ParticipantRepository domain.ParticipantRepository
+	SprintRepository      domain.SprintRepository
+	AttachmentRepository  domain.AttachmentRepository
+	BlobStore             domain.BlobStore
+	Events                domain.EventPublisher
+	SLA                   domain.SLA
+}
+
+func (is IssueService) Issue(id int64) (*domain.Issue, error) {
+	issue, err := is.IssueRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	return issue, is.decorate(issue)
+}
+
+func (is IssueService) Issues(filter domain.IssueFilter) ([]*domain.Issue, error) {
+	issues, err := is.IssueRepository.Find(filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		if err := is.decorate(issue); err != nil {
+			return nil, err
+		}
+	}
+	return issues, nil
+}
+
+func (is IssueService) Search(query string, userId int64) ([]*domain.Issue, error) {
+	q, err := domain.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := is.IssueRepository.Query(q, userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		if err := is.decorate(issue); err != nil {
+			return nil, err
+		}
+	}
+	return issues, nil
 }
 
-func (is IssueService) Issue(id int64) (*domain.Issue, error) { return nil, nil }
-func (is IssueService) Issues() ([]*domain.Issue, error)      { return is.IssueRepository.All() }
+// decorate loads the labels, the custom fields and the participants of the issue
+// and works out when it is due.
+func (is IssueService) decorate(issue *domain.Issue) error {
+	labels, err := is.LabelRepository.ForIssue(issue.Id)
+	if err != nil {
+		return err
+	}
+	fields, err := issueFields(is.FieldRepository, issue.Id)
+	if err != nil {
+		return err
+	}
+	assignees, err := is.ParticipantRepository.Assignees(issue.Id)
+	if err != nil {
+		return err
+	}
+	watchers, err := is.ParticipantRepository.Watchers(issue.Id)
+	if err != nil {
+		return err
+	}
+	issue.Labels, issue.Fields = labels, fields
+	issue.Assignees, issue.Watchers = assignees, watchers
+	issue.DueBy = is.SLA.DueBy(issue)
+	return nil
+}
+
+// Create puts the new issue in the initial state of its project workflow.
 func (is IssueService) Create(issue *domain.Issue) error {
-	return is.IssueRepository.Create(issue)
+	if err := is.validate(issue); err != nil {
+		return err
+	}
+	workflow, err := is.workflow(issue.ProjectId)
+	if err != nil {
+		return err
+	}
+	issue.Status = workflow.Initial
+	issue.CreatedAt = time.Now().UTC()
+	issue.UpdatedAt = issue.CreatedAt
+	snapshot, err := json.Marshal(issue)
+	if err != nil {
+		return err
+	}
+	created := &domain.IssueEvent{
+		Type:    domain.EventIssueCreated,
+		To:      string(snapshot),
+		Message: fmt.Sprintf("issue %q was created", issue.Title),
+		At:      issue.CreatedAt,
+	}
+	if err := is.IssueRepository.Create(issue, created); err != nil {
+		return err
+	}
+	publish(is.Events, *created)
+	return nil
+}
+
+// Update changes everything but the status, which only moves through Transition,
+// and records an event for each changed field. An issue moved to a project
+// whose workflow lacks its status starts over in the initial state.
+func (is IssueService) Update(issue *domain.Issue) error {
+	if err := is.validate(issue); err != nil {
+		return err
+	}
+	stored, err := is.IssueRepository.GetById(issue.Id)
+	if err != nil {
+		return err
+	}
+	issue.Status = stored.Status
+	issue.CreatedAt = stored.CreatedAt
+	issue.UpdatedAt = time.Now().UTC()
+	var history []*domain.HistoryEntry
+	if issue.ProjectId != stored.ProjectId {
+		workflow, err := is.workflow(issue.ProjectId)
+		if err != nil {
+			return err
+		}
+		if !workflow.HasState(issue.Status) {
+			issue.Status = workflow.Initial
+			history = append(history, &domain.HistoryEntry{
+				IssueId: issue.Id,
+				Field:   "status",
+				From:    string(stored.Status),
+				To:      string(issue.Status),
+				At:      issue.UpdatedAt,
+			})
+		}
+	}
+	changes := domain.ChangedFields(stored, issue)
+	events := make([]*domain.IssueEvent, len(changes))
+	for n := range changes {
+		changes[n].At = issue.UpdatedAt
+		events[n] = &changes[n]
+	}
+	if err := is.IssueRepository.Update(issue, history, events...); err != nil {
+		return err
+	}
+	for _, e := range events {
+		publish(is.Events, *e)
+	}
+	return nil
+}
+
+// Delete also drops the contents of the attachments no other issue uses.
+func (is IssueService) Delete(id int64) error {
+	var attachments []*domain.Attachment
+	if is.AttachmentRepository != nil {
+		var err error
+		if attachments, err = is.AttachmentRepository.ForIssue(id); err != nil {
+			return err
+		}
+	}
+	deleted := &domain.IssueEvent{
+		Type:    domain.EventIssueDeleted,
+		IssueId: id,
+		Message: fmt.Sprintf("issue %d was deleted", id),
+		At:      time.Now().UTC(),
+	}
+	if err := is.IssueRepository.Delete(id, deleted); err != nil {
+		return err
+	}
+	for _, a := range attachments {
+		if err := releaseContent(is.AttachmentRepository, is.BlobStore, a.Digest); err != nil {
+			log.Printf("fail to release the content of attachment %d: %v", a.Id, err)
+		}
+	}
+	publish(is.Events, *deleted)
+	return nil
+}
+
+// Transition moves the issue to another state if its project workflow allows it
+// and records the change in the issue history.
+func (is IssueService) Transition(id int64, to domain.Status, userId int64) (*domain.Issue, error) {
+	issue, err := is.Issue(id)
+	if err != nil {
+		return nil, err
+	}
+	workflow, err := is.workflow(issue.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	if err := workflow.Allow(issue, to); err != nil {
+		return nil, err
+	}
+
+	from := issue.Status
+	issue.Status = to
+	issue.UpdatedAt = time.Now().UTC()
+	entry := &domain.HistoryEntry{
+		IssueId: issue.Id,
+		UserId:  userId,
+		Field:   "status",
+		From:    string(from),
+		To:      string(to),
+		At:      issue.UpdatedAt,
+	}
+	transitioned := &domain.IssueEvent{
+		Type:    domain.EventIssueTransitioned,
+		IssueId: issue.Id,
+		ActorId: userId,
+		Field:   "status",
+		From:    string(from),
+		To:      string(to),
+		Message: fmt.Sprintf("issue %d moved from %s to %s", issue.Id, from, to),
+		At:      issue.UpdatedAt,
+	}
+	if err := is.IssueRepository.Update(issue, []*domain.HistoryEntry{entry}, transitioned); err != nil {
+		return nil, err
+	}
+	publish(is.Events, *transitioned)
+	return issue, nil
+}
+
+func (is IssueService) History(id int64) ([]*domain.HistoryEntry, error) {
+	if _, err := is.IssueRepository.GetById(id); err != nil {
+		return nil, err
+	}
+	return is.HistoryRepository.ForIssue(id)
+}
+
+func (is IssueService) Breaching() ([]*domain.Issue, error) {
+	issues, err := is.IssueRepository.CreatedBefore(is.SLA.Cutoffs(time.Now().UTC()), domain.DoneStatuses)
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		if err := is.decorate(issue); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].DueBy.Before(*issues[j].DueBy)
+	})
+	return issues, nil
+}
+
+func (is IssueService) workflow(projectId int64) (*domain.Workflow, error) {
+	workflow, err := is.WorkflowRepository.Workflow(projectId)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.DefaultWorkflow(projectId), nil
+	}
+	return workflow, err
+}
+
+func (is IssueService) validate(issue *domain.Issue) error {
+	issue.Title = strings.TrimSpace(issue.Title)
+	if issue.Title == "" {
+		return domain.NewValidationError("issue title is required")
+	}
+	if !issue.Priority.IsValid() {
+		return domain.NewValidationError(fmt.Sprintf("priority must be one of %v", domain.Priorities))
+	}
+	if issue.Points < 0 {
+		return domain.NewValidationError("story points can't be negative")
+	}
+	if err := is.checkProject(issue.ProjectId); err != nil {
+		return err
+	}
+	return is.checkSprint(issue)
+}
+
+// checkSprint keeps issues in the sprints of their own project.
+func (is IssueService) checkSprint(issue *domain.Issue) error {
+	if issue.SprintId == 0 {
+		return nil
+	}
+	sprint, err := is.SprintRepository.GetById(issue.SprintId)
+	if errors.Is(err, domain.ErrNotFound) || (err == nil && sprint.ProjectId != issue.ProjectId) {
+		return domain.NewValidationError(fmt.Sprintf("sprint %d is not in project %d", issue.SprintId, issue.ProjectId))
+	}
+	return err
+}
+
+// checkProject makes sure issues only reference existing projects.
+func (is IssueService) checkProject(projectId int64) error {
+	_, err := is.ProjectRepository.GetById(projectId)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.NewValidationError(fmt.Sprintf("project %d does not exist", projectId))
+	}
+	return err
 }
-func (is IssueService) Delete(id int64) error { return nil }
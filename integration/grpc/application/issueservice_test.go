@@ -0,0 +1,96 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/pwera/ddd/domain"
+	"github.com/pwera/ddd/persistence/db"
+)
+
+func TestIssueService_MovingProjectsResetsTheStatus(t *testing.T) {
+	conn, err := db.Open(db.DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	projects := db.NewProjectRepository(conn)
+	workflows := db.NewWorkflowRepository(conn)
+	activity := ActivityService{
+		EventRepository: db.NewEventRepository(conn),
+		IssueRepository: db.NewIssueRepository(conn),
+	}
+	bus := &EventBus{}
+	bus.Subscribe(activity.Record)
+	issues := IssueService{
+		IssueRepository:       db.NewIssueRepository(conn),
+		ProjectRepository:     projects,
+		WorkflowRepository:    workflows,
+		HistoryRepository:     db.NewHistoryRepository(conn),
+		LabelRepository:       db.NewLabelRepository(conn),
+		FieldRepository:       db.NewFieldRepository(conn),
+		ParticipantRepository: db.NewParticipantRepository(conn),
+		Events:                bus,
+	}
+
+	from, to := &domain.Project{Name: "From"}, &domain.Project{Name: "To"}
+	for _, p := range []*domain.Project{from, to} {
+		if err := projects.Create(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = workflows.Save(&domain.Workflow{
+		ProjectId:   to.Id,
+		Initial:     "Todo",
+		States:      []domain.Status{"Todo", "Done"},
+		Transitions: []domain.Transition{{From: []domain.Status{"Todo"}, To: "Done"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := &domain.Issue{Title: "Bug", ProjectId: from.Id, OwnerId: 7, Priority: domain.PriorityLow}
+	if err := issues.Create(issue); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := issues.Transition(issue.Id, domain.StatusInProgress, 7); err != nil {
+		t.Fatal(err)
+	}
+	issue.ProjectId = to.Id
+	if err := issues.Update(issue); err != nil {
+		t.Fatal(err)
+	}
+	if issue.Status != "Todo" {
+		t.Fatalf("expected the issue to start over in Todo but got %q", issue.Status)
+	}
+	if _, err := issues.Transition(issue.Id, "Done", 7); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := issues.History(issue.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Open>In Progress", "In Progress>Todo", "Todo>Done"}
+	if len(history) != len(want) {
+		t.Fatalf("expected %d history entries but got %d", len(want), len(history))
+	}
+	for n, e := range history {
+		if got := e.From + ">" + e.To; got != want[n] {
+			t.Fatalf("expected history entry %d to be %s but got %s", n, want[n], got)
+		}
+	}
+	events, err := activity.Activity(issue.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 5 {
+		t.Fatalf("expected the creation, two transitions and the project and status changes once each but got %d events", len(events))
+	}
+	c, err := activity.Check(issue.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Differences) != 0 {
+		t.Fatalf("expected the events to replay the issue but got %v", c.Differences)
+	}
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pwera/ddd/domain"
+)
+
+var labelColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type LabelService struct {
+	LabelRepository   domain.LabelRepository
+	ProjectRepository domain.ProjectRepository
+	IssueRepository   domain.IssueRepository
+}
+
+func (ls LabelService) Labels(projectId int64) ([]*domain.Label, error) {
+	if _, err := ls.ProjectRepository.GetById(projectId); err != nil {
+		return nil, err
+	}
+	return ls.LabelRepository.ForProject(projectId)
+}
+
+func (ls LabelService) Create(l *domain.Label) error {
+	if _, err := ls.ProjectRepository.GetById(l.ProjectId); err != nil {
+		return err
+	}
+	l.Name = strings.TrimSpace(l.Name)
+	if l.Name == "" {
+		return domain.NewValidationError("label name is required")
+	}
+	if !labelColor.MatchString(l.Color) {
+		return domain.NewValidationError("label color must look like #rrggbb")
+	}
+	return ls.LabelRepository.Create(l)
+}
+
+func (ls LabelService) Delete(projectId, id int64) error {
+	if _, err := ls.label(projectId, id); err != nil {
+		return err
+	}
+	return ls.LabelRepository.Delete(id)
+}
+
+// Attach only accepts labels of the project of the issue.
+func (ls LabelService) Attach(issueId, labelId int64) error {
+	issue, err := ls.IssueRepository.GetById(issueId)
+	if err != nil {
+		return err
+	}
+	label, err := ls.LabelRepository.GetById(labelId)
+	if err != nil {
+		return err
+	}
+	if label.ProjectId != issue.ProjectId {
+		return domain.NewValidationError(fmt.Sprintf("label %d belongs to another project", labelId))
+	}
+	return ls.LabelRepository.Attach(issueId, labelId)
+}
+
+func (ls LabelService) Detach(issueId, labelId int64) error {
+	if _, err := ls.IssueRepository.GetById(issueId); err != nil {
+		return err
+	}
+	return ls.LabelRepository.Detach(issueId, labelId)
+}
+
+func (ls LabelService) label(projectId, id int64) (*domain.Label, error) {
+	l, err := ls.LabelRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if l.ProjectId != projectId {
+		return nil, domain.ErrNotFound
+	}
+	return l, nil
+}
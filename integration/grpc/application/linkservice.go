@@ -0,0 +1,121 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type LinkService struct {
+	LinkRepository    domain.LinkRepository
+	IssueRepository   domain.IssueRepository
+	ProjectRepository domain.ProjectRepository
+}
+
+func (ls LinkService) Links(issueId int64) ([]*domain.IssueLink, error) {
+	if _, err := ls.IssueRepository.GetById(issueId); err != nil {
+		return nil, err
+	}
+	return ls.LinkRepository.ForIssue(issueId)
+}
+
+// Link refuses blocking and parent links that would loop back to the issue.
+func (ls LinkService) Link(issueId, otherId int64, linkType string) (*domain.IssueLink, error) {
+	if _, err := ls.IssueRepository.GetById(issueId); err != nil {
+		return nil, err
+	}
+	if _, err := ls.IssueRepository.GetById(otherId); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.NewValidationError(fmt.Sprintf("issue %d does not exist", otherId))
+		}
+		return nil, err
+	}
+	link, err := domain.NewIssueLink(issueId, otherId, linkType)
+	if err != nil {
+		return nil, err
+	}
+	if err := ls.LinkRepository.Create(link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (ls LinkService) Unlink(issueId, linkId int64) error {
+	link, err := ls.LinkRepository.GetById(linkId)
+	if err != nil {
+		return err
+	}
+	if link.FromId != issueId && link.ToId != issueId {
+		return domain.ErrNotFound
+	}
+	return ls.LinkRepository.Delete(linkId)
+}
+
+// Blockers leaves out the blocking issues that were deleted.
+func (ls LinkService) Blockers(issueId int64) ([]*domain.Issue, error) {
+	if _, err := ls.IssueRepository.GetById(issueId); err != nil {
+		return nil, err
+	}
+	ids, err := ls.LinkRepository.Upstream(issueId, domain.LinkBlocks)
+	if err != nil {
+		return nil, err
+	}
+	blockers := make([]*domain.Issue, 0, len(ids))
+	for _, id := range ids {
+		issue, err := ls.IssueRepository.GetById(id)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		blockers = append(blockers, issue)
+	}
+	return blockers, nil
+}
+
+// DependencyGraph has a node for every issue of the project and for the issues
+// of other projects they are linked to.
+func (ls LinkService) DependencyGraph(projectId int64) (*domain.DependencyGraph, error) {
+	if _, err := ls.ProjectRepository.GetById(projectId); err != nil {
+		return nil, err
+	}
+	issues, err := ls.IssueRepository.Find(domain.IssueFilter{ProjectId: projectId})
+	if err != nil {
+		return nil, err
+	}
+	links, err := ls.LinkRepository.ForProject(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &domain.DependencyGraph{ProjectId: projectId, Nodes: []*domain.GraphNode{}, Edges: []*domain.IssueLink{}}
+	nodes := map[int64]bool{}
+	for _, issue := range issues {
+		graph.Nodes = append(graph.Nodes, &domain.GraphNode{Id: issue.Id, Title: issue.Title, Status: issue.Status})
+		nodes[issue.Id] = true
+	}
+	for _, link := range links {
+		missing := false
+		for _, id := range []int64{link.FromId, link.ToId} {
+			if nodes[id] {
+				continue
+			}
+			issue, err := ls.IssueRepository.GetById(id)
+			if errors.Is(err, domain.ErrNotFound) {
+				missing = true
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			graph.Nodes = append(graph.Nodes, &domain.GraphNode{Id: issue.Id, Title: issue.Title, Status: issue.Status, External: true})
+			nodes[id] = true
+		}
+		if !missing {
+			graph.Edges = append(graph.Edges, link)
+		}
+	}
+	return graph, nil
+}
@@ -0,0 +1,67 @@
+package application
+
+import (
+	"github.com/pwera/ddd/domain"
+)
+
+type NotificationService struct {
+	NotificationRepository domain.NotificationRepository
+	ParticipantRepository  domain.ParticipantRepository
+	UserRepository         domain.UserRepository
+}
+
+func (ns NotificationService) Notifications(userId int64, unreadOnly bool) ([]*domain.Notification, error) {
+	if _, err := ns.UserRepository.User(userId); err != nil {
+		return nil, err
+	}
+	return ns.NotificationRepository.ForUser(userId, unreadOnly)
+}
+
+func (ns NotificationService) MarkRead(userId, id int64) error {
+	n, err := ns.NotificationRepository.GetById(id)
+	if err != nil {
+		return err
+	}
+	if n.UserId != userId {
+		return domain.ErrNotFound
+	}
+	return ns.NotificationRepository.MarkRead(id)
+}
+
+func (ns NotificationService) MarkAllRead(userId int64) error {
+	if _, err := ns.UserRepository.User(userId); err != nil {
+		return err
+	}
+	return ns.NotificationRepository.MarkAllRead(userId)
+}
+
+// Handle puts the event in the inbox of the assignees and the watchers of the issue,
+// the user who caused it is left out.
+func (ns NotificationService) Handle(e domain.IssueEvent) error {
+	assignees, err := ns.ParticipantRepository.Assignees(e.IssueId)
+	if err != nil {
+		return err
+	}
+	watchers, err := ns.ParticipantRepository.Watchers(e.IssueId)
+	if err != nil {
+		return err
+	}
+	notified := map[int64]bool{e.ActorId: true}
+	for _, userId := range append(assignees, watchers...) {
+		if notified[userId] {
+			continue
+		}
+		notified[userId] = true
+		err := ns.NotificationRepository.Create(&domain.Notification{
+			UserId:    userId,
+			IssueId:   e.IssueId,
+			Event:     e.Type,
+			Message:   e.Message,
+			CreatedAt: e.At,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package application
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+	"github.com/pwera/ddd/persistence/db"
+)
+
+func TestNotificationService_Handle(t *testing.T) {
+	conn, err := db.Open(db.DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	issues := db.NewIssueRepository(conn)
+	users := db.NewUserRepository(conn)
+	participants := db.NewParticipantRepository(conn)
+	notifications := NotificationService{
+		NotificationRepository: db.NewNotificationRepository(conn),
+		ParticipantRepository:  participants,
+		UserRepository:         users,
+	}
+	bus := &EventBus{}
+	bus.Subscribe(func(e domain.IssueEvent) error {
+		return errors.New("a failing handler")
+	})
+	bus.Subscribe(notifications.Handle)
+	assign := ParticipantService{
+		ParticipantRepository: participants,
+		IssueRepository:       issues,
+		UserRepository:        users,
+		Events:                bus,
+	}
+	comments := CommentService{
+		CommentRepository:     db.NewCommentRepository(conn),
+		IssueRepository:       issues,
+		UserRepository:        users,
+		ParticipantRepository: participants,
+		Events:                bus,
+	}
+
+	now := time.Now().UTC()
+	issue := &domain.Issue{Title: "Bug", ProjectId: 1, Priority: domain.PriorityLow, Status: domain.StatusOpen, CreatedAt: now, UpdatedAt: now}
+	if err := issues.Create(issue); err != nil {
+		t.Fatal(err)
+	}
+	bob, carol := &domain.User{Name: "Bob", Email: "bob@example.com"}, &domain.User{Name: "Carol", Email: "carol@example.com"}
+	for _, u := range []*domain.User{bob, carol} {
+		if err := users.Create(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// bob is an assignee and a watcher but hears of each event once
+	if err := assign.Assign(issue.Id, bob.Id); err != nil {
+		t.Fatalf("expected the assignment to survive the failing handler but got %v", err)
+	}
+	if err := comments.Create(&domain.Comment{IssueId: issue.Id, AuthorId: carol.Id, Body: "on it"}); err != nil {
+		t.Fatal(err)
+	}
+	watchers, err := participants.Watchers(issue.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(watchers) != 2 {
+		t.Fatalf("expected the assignee and the commenter to watch the issue but got %v", watchers)
+	}
+	if err := assign.Unwatch(issue.Id, carol.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	inbox, err := notifications.Notifications(bob.Id, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.EventType{domain.EventIssueAssigned, domain.EventIssueCommented, domain.EventIssueUnwatched}
+	if len(inbox) != len(want) {
+		t.Fatalf("expected bob to get %d notifications but got %+v", len(want), inbox)
+	}
+	for _, n := range inbox {
+		found := false
+		for _, e := range want {
+			found = found || n.Event == e
+		}
+		if !found {
+			t.Fatalf("unexpected notification %+v", n)
+		}
+	}
+	inbox, err = notifications.Notifications(carol.Id, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inbox) != 0 {
+		t.Fatalf("expected carol not to hear of her own changes but got %+v", inbox)
+	}
+}
@@ -0,0 +1,85 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type ParticipantService struct {
+	ParticipantRepository domain.ParticipantRepository
+	IssueRepository       domain.IssueRepository
+	UserRepository        domain.UserRepository
+	Events                domain.EventPublisher
+}
+
+// Assign also makes the user watch the issue.
+func (ps ParticipantService) Assign(issueId, userId int64) error {
+	if err := ps.check(issueId, userId); err != nil {
+		return err
+	}
+	if err := ps.ParticipantRepository.Assign(issueId, userId); err != nil {
+		return err
+	}
+	if err := ps.ParticipantRepository.Watch(issueId, userId); err != nil {
+		return err
+	}
+	publish(ps.Events, domain.IssueEvent{
+		Type:    domain.EventIssueAssigned,
+		IssueId: issueId,
+		Message: fmt.Sprintf("user %d was assigned to issue %d", userId, issueId),
+	})
+	return nil
+}
+
+func (ps ParticipantService) Unassign(issueId, userId int64) error {
+	if _, err := ps.IssueRepository.GetById(issueId); err != nil {
+		return err
+	}
+	if err := ps.ParticipantRepository.Unassign(issueId, userId); err != nil {
+		return err
+	}
+	publish(ps.Events, domain.IssueEvent{
+		Type:    domain.EventIssueUnassigned,
+		IssueId: issueId,
+		Message: fmt.Sprintf("user %d was unassigned from issue %d", userId, issueId),
+	})
+	return nil
+}
+
+func (ps ParticipantService) Watch(issueId, userId int64) error {
+	if err := ps.check(issueId, userId); err != nil {
+		return err
+	}
+	return ps.ParticipantRepository.Watch(issueId, userId)
+}
+
+func (ps ParticipantService) Unwatch(issueId, userId int64) error {
+	if _, err := ps.IssueRepository.GetById(issueId); err != nil {
+		return err
+	}
+	if err := ps.ParticipantRepository.Unwatch(issueId, userId); err != nil {
+		return err
+	}
+	publish(ps.Events, domain.IssueEvent{
+		Type:    domain.EventIssueUnwatched,
+		IssueId: issueId,
+		ActorId: userId,
+		Message: fmt.Sprintf("user %d stopped watching issue %d", userId, issueId),
+	})
+	return nil
+}
+
+func (ps ParticipantService) check(issueId, userId int64) error {
+	if _, err := ps.IssueRepository.GetById(issueId); err != nil {
+		return err
+	}
+	if _, err := ps.UserRepository.User(userId); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.NewValidationError(fmt.Sprintf("user %d does not exist", userId))
+		}
+		return err
+	}
+	return nil
+}
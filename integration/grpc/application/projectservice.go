@@ -0,0 +1,69 @@
+package application
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type ProjectService struct {
+	ProjectRepository  domain.ProjectRepository
+	WorkflowRepository domain.WorkflowRepository
+}
+
+func (ps ProjectService) Project(id int64) (*domain.Project, error) {
+	return ps.ProjectRepository.GetById(id)
+}
+
+func (ps ProjectService) Projects() ([]*domain.Project, error) {
+	return ps.ProjectRepository.All()
+}
+
+func (ps ProjectService) Create(p *domain.Project) error {
+	if err := ps.validate(p); err != nil {
+		return err
+	}
+	return ps.ProjectRepository.Create(p)
+}
+
+func (ps ProjectService) Update(p *domain.Project) error {
+	if err := ps.validate(p); err != nil {
+		return err
+	}
+	return ps.ProjectRepository.Update(p)
+}
+
+func (ps ProjectService) Delete(id int64) error {
+	return ps.ProjectRepository.Delete(id)
+}
+
+// Workflow returns the workflow of the project, the default one if none was set.
+func (ps ProjectService) Workflow(projectId int64) (*domain.Workflow, error) {
+	if _, err := ps.ProjectRepository.GetById(projectId); err != nil {
+		return nil, err
+	}
+	workflow, err := ps.WorkflowRepository.Workflow(projectId)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.DefaultWorkflow(projectId), nil
+	}
+	return workflow, err
+}
+
+func (ps ProjectService) SetWorkflow(w *domain.Workflow) error {
+	if _, err := ps.ProjectRepository.GetById(w.ProjectId); err != nil {
+		return err
+	}
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	return ps.WorkflowRepository.Save(w)
+}
+
+func (ps ProjectService) validate(p *domain.Project) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return domain.NewValidationError("project name is required")
+	}
+	return nil
+}
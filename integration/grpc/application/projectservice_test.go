@@ -0,0 +1,57 @@
+package application
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pwera/ddd/domain"
+	"github.com/pwera/ddd/persistence/db"
+)
+
+func TestProjectService_IssuesNeedTheirProject(t *testing.T) {
+	conn, err := db.Open(db.DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	projects := ProjectService{
+		ProjectRepository:  db.NewProjectRepository(conn),
+		WorkflowRepository: db.NewWorkflowRepository(conn),
+	}
+	issues := IssueService{
+		IssueRepository:    db.NewIssueRepository(conn),
+		ProjectRepository:  projects.ProjectRepository,
+		WorkflowRepository: projects.WorkflowRepository,
+		HistoryRepository:  db.NewHistoryRepository(conn),
+	}
+
+	var validation *domain.ValidationError
+	if err := projects.Create(&domain.Project{Name: "  "}); !errors.As(err, &validation) {
+		t.Fatalf("expected a validation error for a blank name but got %v", err)
+	}
+	p := &domain.Project{Name: " Tracker "}
+	if err := projects.Create(p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Tracker" {
+		t.Fatalf("expected the name to be trimmed but got %q", p.Name)
+	}
+
+	if err := issues.Create(&domain.Issue{Title: "Bug", ProjectId: 99, Priority: domain.PriorityLow}); err == nil {
+		t.Fatal("expected an issue in a missing project to be refused")
+	}
+	issue := &domain.Issue{Title: "Bug", ProjectId: p.Id, Priority: domain.PriorityLow}
+	if err := issues.Create(issue); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := projects.Delete(p.Id); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict deleting a project with issues but got %v", err)
+	}
+	if err := issues.Delete(issue.Id); err != nil {
+		t.Fatal(err)
+	}
+	if err := projects.Delete(p.Id); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,62 @@
+package application
+
+import (
+	"strings"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type SavedQueryService struct {
+	SavedQueryRepository domain.SavedQueryRepository
+	UserRepository       domain.UserRepository
+	IssueService         domain.IssueService
+}
+
+func (ss SavedQueryService) Queries(userId int64) ([]*domain.SavedQuery, error) {
+	if _, err := ss.UserRepository.User(userId); err != nil {
+		return nil, err
+	}
+	return ss.SavedQueryRepository.ForUser(userId)
+}
+
+// Save only keeps queries that parse.
+func (ss SavedQueryService) Save(q *domain.SavedQuery) error {
+	if _, err := ss.UserRepository.User(q.UserId); err != nil {
+		return err
+	}
+	q.Name = strings.TrimSpace(q.Name)
+	if q.Name == "" {
+		return domain.NewValidationError("query name is required")
+	}
+	if _, err := domain.ParseQuery(q.Query); err != nil {
+		return err
+	}
+	return ss.SavedQueryRepository.Create(q)
+}
+
+func (ss SavedQueryService) Delete(userId, id int64) error {
+	if _, err := ss.query(userId, id); err != nil {
+		return err
+	}
+	return ss.SavedQueryRepository.Delete(id)
+}
+
+// Run searches the issues with "me" being the owner of the query.
+func (ss SavedQueryService) Run(userId, id int64) ([]*domain.Issue, error) {
+	q, err := ss.query(userId, id)
+	if err != nil {
+		return nil, err
+	}
+	return ss.IssueService.Search(q.Query, userId)
+}
+
+func (ss SavedQueryService) query(userId, id int64) (*domain.SavedQuery, error) {
+	q, err := ss.SavedQueryRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if q.UserId != userId {
+		return nil, domain.ErrNotFound
+	}
+	return q, nil
+}
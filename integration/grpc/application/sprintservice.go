@@ -0,0 +1,106 @@
+package application
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type SprintService struct {
+	SprintRepository  domain.SprintRepository
+	ProjectRepository domain.ProjectRepository
+	IssueRepository   domain.IssueRepository
+	EventRepository   domain.EventRepository
+}
+
+func (ss SprintService) Sprints(projectId int64) ([]*domain.Sprint, error) {
+	if _, err := ss.ProjectRepository.GetById(projectId); err != nil {
+		return nil, err
+	}
+	return ss.SprintRepository.ForProject(projectId)
+}
+
+// Create keeps only the days of Start and End.
+func (ss SprintService) Create(s *domain.Sprint) error {
+	if _, err := ss.ProjectRepository.GetById(s.ProjectId); err != nil {
+		return err
+	}
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return domain.NewValidationError("sprint name is required")
+	}
+	if s.Kind == "" {
+		s.Kind = domain.SprintKindSprint
+	}
+	if s.Kind != domain.SprintKindSprint && s.Kind != domain.SprintKindMilestone {
+		return domain.NewValidationError("sprint kind must be sprint or milestone")
+	}
+	if s.Start.IsZero() || s.End.IsZero() {
+		return domain.NewValidationError("sprint start and end are required")
+	}
+	s.Start, s.End = domain.Day(s.Start), domain.Day(s.End)
+	if s.End.Before(s.Start) {
+		return domain.NewValidationError("sprint can't end before it starts")
+	}
+	return ss.SprintRepository.Create(s)
+}
+
+func (ss SprintService) Delete(projectId, id int64) error {
+	if _, err := ss.sprint(projectId, id); err != nil {
+		return err
+	}
+	return ss.SprintRepository.Delete(id)
+}
+
+// Report replays the history of every issue that was ever in the sprint.
+func (ss SprintService) Report(projectId, id int64) (*domain.SprintReport, error) {
+	sprint, err := ss.sprint(projectId, id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := ss.IssueRepository.Find(domain.IssueFilter{SprintId: id})
+	if err != nil {
+		return nil, err
+	}
+	issues := map[int64]*domain.Issue{}
+	for _, issue := range current {
+		issues[issue.Id] = issue
+	}
+	touched, err := ss.EventRepository.InSprint(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, issueId := range touched {
+		if _, ok := issues[issueId]; ok {
+			continue
+		}
+		issue, err := ss.IssueRepository.GetById(issueId)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		issues[issueId] = issue
+	}
+
+	timelines := make([]domain.IssueTimeline, 0, len(issues))
+	for issueId, issue := range issues {
+		events, err := ss.EventRepository.ForIssue(issueId)
+		if err != nil {
+			return nil, err
+		}
+		timelines = append(timelines, domain.IssueTimeline{Current: issue, Events: events})
+	}
+	return domain.Burndown(sprint, timelines, time.Now().UTC()), nil
+}
+
+func (ss SprintService) sprint(projectId, id int64) (*domain.Sprint, error) {
+	s, err := ss.SprintRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.ProjectId != projectId {
+		return nil, domain.ErrNotFound
+	}
+	return s, nil
+}
@@ -1,7 +1,19 @@
 package application
 
 import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
 	"github.com/pwera/ddd/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcrypt only hashes the first 72 bytes of a password.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
 )
 
 type UserService struct {
@@ -13,8 +25,47 @@ func (us UserService) Users() ([]*domain.User, error) {
 }
 
 func (us UserService) Create(u *domain.User) error {
+	if err := us.validate(u); err != nil {
+		return err
+	}
 	return us.UserRepository.Create(u)
 }
+
+func (us UserService) Register(name, email, password string) (*domain.User, error) {
+	u := &domain.User{Name: name, Email: email}
+	if err := us.validate(u); err != nil {
+		return nil, err
+	}
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return nil, domain.NewValidationError(fmt.Sprintf("a password of %d to %d bytes is required", MinPasswordLength, MaxPasswordLength))
+	}
+	// The unique email index still settles concurrent registrations, this only
+	// saves hashing the password of a registration that can't succeed.
+	_, err := us.UserRepository.ByEmail(u.Email)
+	if err == nil {
+		return nil, fmt.Errorf("email %s is already taken: %w", u.Email, domain.ErrConflict)
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	u.PasswordHash = string(hash)
+	if err := us.UserRepository.Create(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (us UserService) Update(u *domain.User) error {
+	if err := us.validate(u); err != nil {
+		return err
+	}
+	return us.UserRepository.Update(u)
+}
+
 func (us UserService) Delete(id int64) error {
 	return us.UserRepository.Delete(id)
 }
@@ -26,3 +77,15 @@ func (us UserService) User(id int64) (*domain.User, error) {
 func (us UserService) All() ([]*domain.User, error) {
 	return us.UserRepository.All()
 }
+
+func (us UserService) validate(u *domain.User) error {
+	u.Name = strings.TrimSpace(u.Name)
+	u.Email = strings.ToLower(strings.TrimSpace(u.Email))
+	if u.Name == "" {
+		return domain.NewValidationError("user name is required")
+	}
+	if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+		return domain.NewValidationError("a valid email is required")
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+	"golang.org/x/net/context"
+)
+
+// The paths where the auth server publishes its keys and its revoked tokens.
+const (
+	KeySetPath      = "/.well-known/jwks.json"
+	RevocationsPath = "/revocations"
+)
+
+// JWK is an Ed25519 public key as described by RFC 8037.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+}
+
+type KeySet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func newJWK(kid string, key ed25519.PublicKey) JWK {
+	return JWK{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(key), Kid: kid, Use: "sig", Alg: "EdDSA"}
+}
+
+func (k JWK) publicKey() (ed25519.PublicKey, error) {
+	if k.Kty != "OKP" || k.Crv != "Ed25519" {
+		return nil, fmt.Errorf("unsupported key %s/%s", k.Kty, k.Crv)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil || len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 key %s", k.Kid)
+	}
+	return ed25519.PublicKey(x), nil
+}
+
+// RemoteKeys verifies access tokens locally with the keys and the revoked
+// tokens published by the auth server, which it fetches again every so often.
+type RemoteKeys struct {
+	baseURL string
+	client  *http.Client
+
+	mu      sync.RWMutex
+	keys    map[string]ed25519.PublicKey
+	revoked map[string]time.Time
+}
+
+func NewRemoteKeys(baseURL string) *RemoteKeys {
+	return &RemoteKeys{
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+		keys:    map[string]ed25519.PublicKey{},
+		revoked: map[string]time.Time{},
+	}
+}
+
+// Refresh replaces the keys and the revoked tokens with the published ones.
+func (k *RemoteKeys) Refresh() error {
+	var set KeySet
+	if err := k.get(KeySetPath, &set); err != nil {
+		return err
+	}
+	var revoked []*domain.RevokedToken
+	if err := k.get(RevocationsPath, &revoked); err != nil {
+		return err
+	}
+	keys := make(map[string]ed25519.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := jwk.publicKey()
+		if err != nil {
+			return err
+		}
+		keys[jwk.Kid] = key
+	}
+	revokedIds := make(map[string]time.Time, len(revoked))
+	for _, t := range revoked {
+		revokedIds[t.Id] = t.ExpiresAt
+	}
+	k.mu.Lock()
+	k.keys, k.revoked = keys, revokedIds
+	k.mu.Unlock()
+	return nil
+}
+
+// RefreshEvery refreshes until ctx is done, failures keep the last keys.
+func (k *RemoteKeys) RefreshEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := k.Refresh(); err != nil {
+			log.Printf("fail to refresh the auth keys: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (k *RemoteKeys) get(path string, v interface{}) error {
+	resp, err := k.client.Get(k.baseURL + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Verify checks the token and refuses the revoked ones.
+func (k *RemoteKeys) Verify(token string) (*domain.AccessToken, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	access, err := parse(token, func(kid string) (ed25519.PublicKey, bool) {
+		key, ok := k.keys[kid]
+		return key, ok
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, revoked := k.revoked[access.Id]; revoked {
+		return nil, fmt.Errorf("access token was revoked: %w", domain.ErrUnauthorized)
+	}
+	return access, nil
+}
@@ -0,0 +1,153 @@
+// Package auth signs and verifies the JWT access tokens of the auth server.
+// Tokens are signed with Ed25519 and the public keys are published as a JWKS,
+// so other servers verify them without calling the auth server.
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pwera/ddd/domain"
+)
+
+const Issuer = "ddd-auth"
+
+// claims carry the family of the refresh tokens of the login as its session,
+// logging out with the access token alone ends the whole login.
+type claims struct {
+	jwt.RegisteredClaims
+	Session string `json:"sid,omitempty"`
+}
+
+// Signer issues access tokens with its private key, it implements domain.TokenIssuer.
+type Signer struct {
+	key ed25519.PrivateKey
+	kid string
+}
+
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	sum := sha256.Sum256(key.Public().(ed25519.PublicKey))
+	return &Signer{key: key, kid: hex.EncodeToString(sum[:8])}
+}
+
+// LoadSigner reads the PKCS #8 PEM key at path and creates it when the file
+// doesn't exist. Without a path the key only lives as long as the process.
+func LoadSigner(path string) (*Signer, error) {
+	if path == "" {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return NewSigner(key), nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return createKey(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 key", path)
+	}
+	return NewSigner(key), nil
+}
+
+func createKey(path string) (*Signer, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, err
+	}
+	return NewSigner(key), nil
+}
+
+func (s *Signer) Issue(userId int64, family string, ttl time.Duration) (string, *domain.AccessToken, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, err
+	}
+	now := time.Now().UTC()
+	access := &domain.AccessToken{
+		Id:        base64.RawURLEncoding.EncodeToString(id),
+		UserId:    userId,
+		Family:    family,
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    Issuer,
+			Subject:   strconv.FormatInt(userId, 10),
+			ID:        access.Id,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(access.ExpiresAt),
+		},
+		Session: family,
+	})
+	token.Header["kid"] = s.kid
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, access, nil
+}
+
+func (s *Signer) Parse(token string) (*domain.AccessToken, error) {
+	return parse(token, func(kid string) (ed25519.PublicKey, bool) {
+		return s.key.Public().(ed25519.PublicKey), kid == s.kid
+	})
+}
+
+// KeySet is the JWKS publishing the public key of the signer.
+func (s *Signer) KeySet() KeySet {
+	return KeySet{Keys: []JWK{newJWK(s.kid, s.key.Public().(ed25519.PublicKey))}}
+}
+
+// parse verifies the signature, the issuer and the expiry of the token with
+// the public key returned by key for the kid of the token.
+func parse(token string, key func(kid string) (ed25519.PublicKey, bool)) (*domain.AccessToken, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		k, ok := key(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		return k, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}), jwt.WithIssuer(Issuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token: %v: %w", err, domain.ErrUnauthorized)
+	}
+	userId, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil || c.ID == "" {
+		return nil, fmt.Errorf("invalid access token subject: %w", domain.ErrUnauthorized)
+	}
+	return &domain.AccessToken{Id: c.ID, UserId: userId, Family: c.Session, ExpiresAt: c.ExpiresAt.Time}, nil
+}
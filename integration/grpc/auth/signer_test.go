@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+)
+
+func TestRemoteKeys_Verify(t *testing.T) {
+	signer, err := LoadSigner("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, access, err := signer.Issue(7, "family", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var revoked []*domain.RevokedToken
+	mux := http.NewServeMux()
+	mux.HandleFunc(KeySetPath, func(w http.ResponseWriter, r *http.Request) { json.NewEncoder(w).Encode(signer.KeySet()) })
+	mux.HandleFunc(RevocationsPath, func(w http.ResponseWriter, r *http.Request) { json.NewEncoder(w).Encode(revoked) })
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	keys := NewRemoteKeys(server.URL)
+	if _, err := keys.Verify(token); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected tokens to be refused before the keys are known but got %v", err)
+	}
+	if err := keys.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	verified, err := keys.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified.UserId != 7 || verified.Id != access.Id || verified.Family != "family" {
+		t.Fatalf("expected the issued token but got %+v", verified)
+	}
+
+	revoked = append(revoked, &domain.RevokedToken{Id: access.Id, ExpiresAt: access.ExpiresAt})
+	if err := keys.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Verify(token); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected the revoked token to be refused but got %v", err)
+	}
+}
+
+func TestSigner_ParseRefusesOtherKeys(t *testing.T) {
+	signer, _ := LoadSigner("")
+	other, _ := LoadSigner("")
+	token, _, err := other.Issue(1, "family", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.Parse(token); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected a token of another key to be refused but got %v", err)
+	}
+	expired, _, _ := signer.Issue(1, "family", -time.Minute)
+	if _, err := signer.Parse(expired); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected an expired token to be refused but got %v", err)
+	}
+}
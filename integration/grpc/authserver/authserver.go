@@ -1,60 +1,199 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/pwera/ddd/application"
+	"github.com/pwera/ddd/auth"
 	"github.com/pwera/ddd/controller"
 	"github.com/pwera/ddd/domain"
-	"github.com/pwera/ddd/persistence/memory"
+	"github.com/pwera/ddd/interceptor"
+	"github.com/pwera/ddd/persistence"
+	"github.com/pwera/ddd/persistence/blob"
+	"github.com/pwera/ddd/persistence/db"
 	"github.com/pwera/ddd/protocol/protocol"
+	"github.com/pwera/ddd/readiness"
+	"github.com/pwera/ddd/tracker"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"log"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 	"time"
 )
 
-type server struct{}
+type server struct {
+	protocol.UnimplementedUserServer
+	users domain.UserService
+	auth  domain.AuthService
+}
 
 func (s *server) SubmitNewUser(context context.Context, ur *protocol.NewUserRequest) (*protocol.NewUserResponse, error) {
-	fmt.Println("OK")
-	return &protocol.NewUserResponse{Status: len(ur.Email) > 0}, nil
+	u, err := s.users.Register(ur.Name, ur.Email, ur.Password)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &protocol.NewUserResponse{Status: true, UserId: u.Id}, nil
+}
+
+func (s *server) Login(ctx context.Context, req *protocol.LoginRequest) (*protocol.TokenResponse, error) {
+	tokens, err := s.auth.Login(req.Email, req.Password)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return tokenResponse(tokens), nil
 }
+
+func (s *server) RefreshToken(ctx context.Context, req *protocol.RefreshTokenRequest) (*protocol.TokenResponse, error) {
+	tokens, err := s.auth.Refresh(req.RefreshToken)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return tokenResponse(tokens), nil
+}
+
+func (s *server) Logout(ctx context.Context, req *protocol.LogoutRequest) (*protocol.LogoutResponse, error) {
+	if err := s.auth.Logout(req.AccessToken, req.RefreshToken); err != nil {
+		return nil, statusError(err)
+	}
+	return &protocol.LogoutResponse{}, nil
+}
+
+func tokenResponse(t *domain.Tokens) *protocol.TokenResponse {
+	return &protocol.TokenResponse{
+		AccessToken:  t.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(t.ExpiresAt).Seconds()),
+		RefreshToken: t.RefreshToken,
+	}
+}
+
+var (
+	dsn             = flag.String("dsn", db.SharedDSN, "SQLite data source name, the tracker's file to serve its users and issues")
+	userStore       = flag.String("user-store", persistence.UserStoreSQLite, "where users are kept: memory or sqlite")
+	sla             = flag.String("sla", domain.DefaultSLA().String(), "time an issue of each priority may stay unresolved, like High=24h,Medium=72h")
+	blobDir         = flag.String("blob-dir", filepath.Join(os.TempDir(), "ddd-attachments"), "the tracker's directory of attachment contents, released when an issue is deleted")
+	signingKey      = flag.String("signing-key", "", "Ed25519 PKCS #8 PEM file signing the access tokens, created when missing, a key per run when empty")
+	accessTTL       = flag.Duration("access-ttl", application.DefaultAccessTTL, "lifetime of the access tokens")
+	refreshTTL      = flag.Duration("refresh-ttl", application.DefaultRefreshTTL, "lifetime of the refresh tokens")
+	pruneInterval   = flag.Duration("prune-interval", time.Hour, "how often the revoked access tokens that expired are forgotten")
+	watchInterval   = flag.Duration("watch-interval", time.Second, "how often WatchIssues looks for new issue events")
+	requireAuth     = flag.Bool("require-auth", true, "refuse the gRPC calls without a valid bearer token, except registering and the token calls")
+	logCalls        = flag.Bool("log-calls", true, "log the method, duration and code of every gRPC call")
+	healthInterval  = flag.Duration("health-interval", 5*time.Second, "how often the readiness of the repositories is checked")
+	healthTimeout   = flag.Duration("health-timeout", time.Second, "how long a readiness check may take")
+	shutdownTimeout = flag.Duration("shutdown-timeout", 15*time.Second, "how long the calls in flight may take to finish after SIGTERM")
+	shutdownDelay   = flag.Duration("shutdown-delay", 2*time.Second, "how long NOT_SERVING is reported after SIGTERM before new calls are refused")
+)
+
+// publicMethods are called without an access token, they hand them out. The
+// reflection lets grpcurl describe the services before logging in.
+var publicMethods = map[string]bool{
+	protocol.User_SubmitNewUser_FullMethodName:                       true,
+	protocol.User_Login_FullMethodName:                               true,
+	protocol.User_RefreshToken_FullMethodName:                        true,
+	protocol.User_Logout_FullMethodName:                              true,
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":      true,
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": true,
+	healthpb.Health_Check_FullMethodName:                             true,
+	healthpb.Health_Watch_FullMethodName:                             true,
+}
+
 func main() {
-	userRepo := memory.NewUserRepository()
-	userService := application.UserService{
-		UserRepository: userRepo,
+	flag.Parse()
+	conn, err := db.Open(*dsn)
+	if err != nil {
+		log.Fatalf("fail to open database: %v", err)
+	}
+	userRepo, err := persistence.NewUserRepository(*userStore, conn)
+	if err != nil {
+		log.Fatal(err)
 	}
+	issueSLA, err := domain.ParseSLA(*sla)
+	if err != nil {
+		log.Fatal(err)
+	}
+	blobStore, err := blob.NewFileStore(*blobDir)
+	if err != nil {
+		log.Fatalf("fail to open blob store: %v", err)
+	}
+	services := tracker.NewServices(conn, userRepo, blobStore, issueSLA)
+	userService := services.Users
+	signer, err := auth.LoadSigner(*signingKey)
+	if err != nil {
+		log.Fatalf("fail to load the signing key: %v", err)
+	}
+	authService := application.AuthService{
+		UserRepository:  userRepo,
+		TokenRepository: db.NewTokenRepository(conn),
+		Tokens:          signer,
+		AccessTTL:       *accessTTL,
+		RefreshTTL:      *refreshTTL,
+	}
+	keyController := controller.KeyController{Signer: signer, AuthService: authService}
 
 	userController := controller.UserController{
 		UserService: userService,
 	}
-	for i := 0; i < 10; i += 1 {
-		err := userService.Create(&domain.User{Name: fmt.Sprintf("User_%d", i)})
-		if err != nil {
-			fmt.Errorf("error invoking userService.Create %s", err)
+	if users, err := userService.Users(); err == nil && len(users) == 0 {
+		for i := 0; i < 10; i += 1 {
+			err := userService.Create(&domain.User{
+				Name:  fmt.Sprintf("User_%d", i),
+				Email: fmt.Sprintf("user_%d@example.com", i),
+			})
+			if err != nil {
+				log.Printf("error invoking userService.Create %s", err)
+			}
 		}
 	}
+	healthServer := health.NewServer()
+	checker := readiness.Checker{
+		Server: healthServer,
+		Services: map[string][]readiness.Check{
+			protocol.User_ServiceDesc.ServiceName:         {conn.PingContext},
+			protocol.IssueTracker_ServiceDesc.ServiceName: {conn.PingContext},
+		},
+		Timeout: *healthTimeout,
+	}
+	checker.Update(context.Background())
+
 	mux := http.NewServeMux()
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	})
+	mux.HandleFunc("/health", checker.Handler)
+	mux.HandleFunc(auth.KeySetPath, keyController.KeySet)
+	mux.HandleFunc(auth.RevocationsPath, keyController.Revocations)
+	mux.Handle("/metrics", promhttp.Handler())
 	mux.HandleFunc("/", userController.List)
 
-	var opts []grpc.ServerOption
-	grpcServer := grpc.NewServer(opts...)
-	s := server{}
+	interceptors := interceptor.Config{
+		Metrics:  interceptor.NewMetrics(prometheus.DefaultRegisterer),
+		Validate: true,
+		Public:   publicMethods,
+	}
+	if *logCalls {
+		interceptors.Logger = log.New(os.Stderr, "grpc ", log.LstdFlags)
+	}
+	if *requireAuth {
+		interceptors.Verifier = authService
+	}
+	grpcServer := grpc.NewServer(interceptors.ServerOptions()...)
+	s := server{users: userService, auth: authService}
 	reflection.Register(grpcServer)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
 	protocol.RegisterUserServer(grpcServer, &s)
+	protocol.RegisterIssueTrackerServer(grpcServer, newTrackerServer(services, *watchInterval))
 	lis, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", 10000))
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	go func() {
-		grpcServer.Serve(lis)
-	}()
 	server := &http.Server{
 		Addr:           ":8091",
 		Handler:        mux,
@@ -64,5 +203,49 @@ func main() {
 		MaxHeaderBytes: 1 << 20,
 	}
 
-	log.Fatal(server.ListenAndServe())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go checker.Run(ctx, *healthInterval)
+	go authService.PruneEvery(ctx, *pruneInterval)
+	errs := make(chan error, 2)
+	go func() {
+		errs <- grpcServer.Serve(lis)
+	}()
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		log.Print("shutting down")
+	case serveErr = <-errs:
+	}
+	stop()
+	// The clients watching the health see NOT_SERVING for the shutdown delay
+	// before the servers stop taking calls.
+	healthServer.Shutdown()
+	time.Sleep(*shutdownDelay)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("fail to drain the HTTP server: %v", err)
+	}
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		// WatchIssues streams don't end on their own.
+		log.Print("fail to drain the gRPC server in time, stopping the remaining calls")
+		grpcServer.Stop()
+	}
+	if err := conn.Close(); err != nil {
+		log.Printf("fail to close database: %v", err)
+	}
+	if serveErr != nil {
+		log.Fatalf("failed to serve: %v", serveErr)
+	}
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pwera/ddd/application"
+	"github.com/pwera/ddd/auth"
+	"github.com/pwera/ddd/persistence/db"
+	"github.com/pwera/ddd/persistence/memory"
+	"github.com/pwera/ddd/protocol/protocol"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSubmitNewUser(t *testing.T) {
+	repo := memory.NewUserRepository()
+	s := server{users: application.UserService{UserRepository: repo}}
+	ctx := context.Background()
+
+	resp, err := s.SubmitNewUser(ctx, &protocol.NewUserRequest{Name: "Ann", Email: "Ann@Example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Status || resp.UserId == 0 {
+		t.Fatalf("expected the new user in the response but got %+v", resp)
+	}
+	u, err := repo.User(resp.UserId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Email != "ann@example.com" || !strings.HasPrefix(u.PasswordHash, "$2") {
+		t.Fatalf("expected a normalized email and a bcrypt hash but got %+v", u)
+	}
+
+	for name, tc := range map[string]struct {
+		req  *protocol.NewUserRequest
+		code codes.Code
+	}{
+		"invalid email":  {&protocol.NewUserRequest{Name: "Bob", Email: "bob", Password: "correct horse"}, codes.InvalidArgument},
+		"short password": {&protocol.NewUserRequest{Name: "Bob", Email: "bob@example.com", Password: "short"}, codes.InvalidArgument},
+		"taken email":    {&protocol.NewUserRequest{Name: "Ann", Email: "ann@example.com", Password: "correct horse"}, codes.AlreadyExists},
+	} {
+		if _, err := s.SubmitNewUser(ctx, tc.req); status.Code(err) != tc.code {
+			t.Errorf("%s: expected %v but got %v", name, tc.code, err)
+		}
+	}
+}
+
+func TestLoginRefreshLogout(t *testing.T) {
+	conn, err := db.Open(db.DefaultDSN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	signer, err := auth.LoadSigner("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := application.UserService{UserRepository: memory.NewUserRepository()}
+	s := server{users: users, auth: application.AuthService{
+		UserRepository:  users.UserRepository,
+		TokenRepository: db.NewTokenRepository(conn),
+		Tokens:          signer,
+		AccessTTL:       time.Minute,
+		RefreshTTL:      time.Hour,
+	}}
+	ctx := context.Background()
+	if _, err := users.Register("Ann", "ann@example.com", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Login(ctx, &protocol.LoginRequest{Email: "ann@example.com", Password: "wrong horse"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected a wrong password to be refused but got %v", err)
+	}
+	login, err := s.Login(ctx, &protocol.LoginRequest{Email: "ann@example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshed, err := s.RefreshToken(ctx, &protocol.RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.RefreshToken == login.RefreshToken {
+		t.Fatal("expected the refresh token to rotate")
+	}
+	// The reuse of the first token revokes the rotated one too.
+	if _, err := s.RefreshToken(ctx, &protocol.RefreshTokenRequest{RefreshToken: login.RefreshToken}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected a reused refresh token to be refused but got %v", err)
+	}
+	if _, err := s.RefreshToken(ctx, &protocol.RefreshTokenRequest{RefreshToken: refreshed.RefreshToken}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected the family of a reused token to be revoked but got %v", err)
+	}
+
+	if _, err := s.Logout(ctx, &protocol.LogoutRequest{AccessToken: refreshed.AccessToken}); err != nil {
+		t.Fatal(err)
+	}
+	revoked, err := s.auth.Revoked()
+	if err != nil {
+		t.Fatal(err)
+	}
+	access, _ := signer.Parse(refreshed.AccessToken)
+	if len(revoked) != 1 || revoked[0].Id != access.Id {
+		t.Fatalf("expected the access token to be revoked but got %+v", revoked)
+	}
+
+	// The access token alone ends its login.
+	login, err = s.Login(ctx, &protocol.LoginRequest{Email: "ann@example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Logout(ctx, &protocol.LogoutRequest{AccessToken: login.AccessToken}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RefreshToken(ctx, &protocol.RefreshTokenRequest{RefreshToken: login.RefreshToken}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected the refresh token of the logged out access token to be refused but got %v", err)
+	}
+}
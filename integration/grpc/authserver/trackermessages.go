@@ -0,0 +1,178 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+	"github.com/pwera/ddd/protocol/protocol"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// statusError maps the domain errors to the gRPC status codes.
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var validation *domain.ValidationError
+	switch {
+	case errors.As(err, &validation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrNotFound), errors.Is(err, sql.ErrNoRows):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrUnauthorized):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, domain.ErrConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// page returns the bounds of the page of n entries and the token of the next
+// page, empty on the last one. The tokens hold the offset of their page.
+func page(n int, pageSize int32, pageToken string) (int, int, string, error) {
+	size := int(pageSize)
+	switch {
+	case size < 0:
+		return 0, 0, "", status.Error(codes.InvalidArgument, "page_size can't be negative")
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+	start := 0
+	if pageToken != "" {
+		offset, err := base64.RawURLEncoding.DecodeString(pageToken)
+		if err == nil {
+			start, err = strconv.Atoi(string(offset))
+		}
+		if err != nil || start < 0 {
+			return 0, 0, "", status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+	}
+	if start > n {
+		start = n
+	}
+	end := start + size
+	if end >= n {
+		return start, n, "", nil
+	}
+	return start, end, base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(end))), nil
+}
+
+// applyMask runs the setter of every path of the mask, or every setter when
+// the mask is empty.
+func applyMask(mask *fieldmaskpb.FieldMask, setters map[string]func()) error {
+	if len(mask.GetPaths()) == 0 {
+		for _, set := range setters {
+			set()
+		}
+		return nil
+	}
+	for _, path := range mask.GetPaths() {
+		set, ok := setters[path]
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "field %q can't be updated", path)
+		}
+		set()
+	}
+	return nil
+}
+
+func userMessage(u *domain.User) *protocol.User {
+	return &protocol.User{Id: u.Id, Name: u.Name, Email: u.Email}
+}
+
+func userSetters(u *domain.User, m *protocol.User) map[string]func() {
+	return map[string]func(){
+		"name":  func() { u.Name = m.Name },
+		"email": func() { u.Email = m.Email },
+	}
+}
+
+func projectMessage(p *domain.Project) *protocol.Project {
+	return &protocol.Project{Id: p.Id, Name: p.Name, OwnerId: p.OwnerId, Description: p.Description}
+}
+
+func projectSetters(p *domain.Project, m *protocol.Project) map[string]func() {
+	return map[string]func(){
+		"name":        func() { p.Name = m.Name },
+		"owner_id":    func() { p.OwnerId = m.OwnerId },
+		"description": func() { p.Description = m.Description },
+	}
+}
+
+func issueMessage(i *domain.Issue) *protocol.Issue {
+	m := &protocol.Issue{
+		Id:          i.Id,
+		Title:       i.Title,
+		Description: i.Description,
+		ProjectId:   i.ProjectId,
+		OwnerId:     i.OwnerId,
+		Priority:    protocol.Priority(i.Priority),
+		Status:      string(i.Status),
+		SprintId:    i.SprintId,
+		Points:      i.Points,
+		CreatedAt:   timestamp(i.CreatedAt),
+		UpdatedAt:   timestamp(i.UpdatedAt),
+		Fields:      i.Fields,
+		AssigneeIds: i.Assignees,
+		WatcherIds:  i.Watchers,
+	}
+	for _, l := range i.Labels {
+		m.Labels = append(m.Labels, l.Name)
+	}
+	if i.DueBy != nil {
+		m.DueBy = timestamppb.New(*i.DueBy)
+	}
+	return m
+}
+
+func issueSetters(i *domain.Issue, m *protocol.Issue) map[string]func() {
+	return map[string]func(){
+		"title":       func() { i.Title = m.Title },
+		"description": func() { i.Description = m.Description },
+		"project_id":  func() { i.ProjectId = m.ProjectId },
+		"owner_id":    func() { i.OwnerId = m.OwnerId },
+		"priority":    func() { i.Priority = domain.Priority(m.Priority) },
+		"sprint_id":   func() { i.SprintId = m.SprintId },
+		"points":      func() { i.Points = m.Points },
+	}
+}
+
+func issueEventMessage(e *domain.IssueEvent) *protocol.IssueEvent {
+	return &protocol.IssueEvent{
+		Id:      e.Id,
+		Type:    string(e.Type),
+		IssueId: e.IssueId,
+		ActorId: e.ActorId,
+		Field:   e.Field,
+		From:    e.From,
+		To:      e.To,
+		Message: e.Message,
+		At:      timestamp(e.At),
+	}
+}
+
+// timestamp leaves unknown times out of the messages.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pwera/ddd/domain"
+	"github.com/pwera/ddd/protocol/protocol"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestPage(t *testing.T) {
+	var pages [][2]int
+	token := ""
+	for {
+		start, end, next, err := page(5, 2, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, [2]int{start, end})
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	if fmt.Sprint(pages) != "[[0 2] [2 4] [4 5]]" {
+		t.Fatalf("unexpected pages %v", pages)
+	}
+	if _, _, _, err := page(5, 2, "not a token"); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected an invalid page token to be refused but got %v", err)
+	}
+}
+
+func TestApplyMask(t *testing.T) {
+	p := &domain.Project{Name: "old", Description: "kept"}
+	m := &protocol.Project{Name: "new", Description: "dropped"}
+	if err := applyMask(&fieldmaskpb.FieldMask{Paths: []string{"name"}}, projectSetters(p, m)); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "new" || p.Description != "kept" {
+		t.Fatalf("expected only the name to change but got %+v", p)
+	}
+	err := applyMask(&fieldmaskpb.FieldMask{Paths: []string{"id"}}, projectSetters(p, m))
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected an unknown path to be refused but got %v", err)
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	for err, code := range map[error]codes.Code{
+		domain.NewValidationError("bad"):                codes.InvalidArgument,
+		fmt.Errorf("user %w", domain.ErrNotFound):       codes.NotFound,
+		fmt.Errorf("taken: %w", domain.ErrConflict):     codes.AlreadyExists,
+		fmt.Errorf("boom"):                              codes.Internal,
+		status.Error(codes.PermissionDenied, "refused"): codes.PermissionDenied,
+	} {
+		if got := status.Code(statusError(err)); got != code {
+			t.Errorf("%v: expected %v but got %v", err, code, got)
+		}
+	}
+}
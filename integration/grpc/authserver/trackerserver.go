@@ -0,0 +1,276 @@
+package main
+
+import (
+	"time"
+
+	"github.com/pwera/ddd/domain"
+	"github.com/pwera/ddd/interceptor"
+	"github.com/pwera/ddd/protocol/protocol"
+	"github.com/pwera/ddd/tracker"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// watchBatch is the most events WatchIssues reads from the log at once.
+const watchBatch = 100
+
+// trackerServer serves the IssueTracker RPCs through the application services.
+type trackerServer struct {
+	protocol.UnimplementedIssueTrackerServer
+	users    domain.UserService
+	projects domain.ProjectService
+	issues   domain.IssueService
+	activity domain.ActivityService
+	// watchInterval is how often WatchIssues looks for new events.
+	watchInterval time.Duration
+}
+
+// newTrackerServer serves the services wired like the tracker's, so the changes
+// made through gRPC are recorded in the same event log and notify the same users.
+func newTrackerServer(services tracker.Services, watchInterval time.Duration) *trackerServer {
+	return &trackerServer{
+		users:         services.Users,
+		projects:      services.Projects,
+		issues:        services.Issues,
+		activity:      services.Activity,
+		watchInterval: watchInterval,
+	}
+}
+
+func (s *trackerServer) GetUser(ctx context.Context, req *protocol.GetUserRequest) (*protocol.User, error) {
+	u, err := s.users.User(req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return userMessage(u), nil
+}
+
+func (s *trackerServer) ListUsers(ctx context.Context, req *protocol.ListUsersRequest) (*protocol.ListUsersResponse, error) {
+	users, err := s.users.Users()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	start, end, next, err := page(len(users), req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	resp := &protocol.ListUsersResponse{NextPageToken: next}
+	for _, u := range users[start:end] {
+		resp.Users = append(resp.Users, userMessage(u))
+	}
+	return resp, nil
+}
+
+// CreateUser refuses to create users without a password, SubmitNewUser
+// registers them.
+func (s *trackerServer) CreateUser(ctx context.Context, req *protocol.CreateUserRequest) (*protocol.User, error) {
+	return nil, status.Error(codes.Unimplemented, "users register with a password through User.SubmitNewUser")
+}
+
+// UpdateUser lets the users only change themselves, as the email they log in
+// with is one of the fields.
+func (s *trackerServer) UpdateUser(ctx context.Context, req *protocol.UpdateUserRequest) (*protocol.User, error) {
+	if req.User == nil {
+		return nil, status.Error(codes.InvalidArgument, "user is required")
+	}
+	if userId, ok := interceptor.AuthenticatedUser(ctx); ok && userId != req.User.Id {
+		return nil, status.Error(codes.PermissionDenied, "users can only update themselves")
+	}
+	u, err := s.users.User(req.User.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if err := applyMask(req.UpdateMask, userSetters(u, req.User)); err != nil {
+		return nil, err
+	}
+	if err := s.users.Update(u); err != nil {
+		return nil, statusError(err)
+	}
+	return userMessage(u), nil
+}
+
+func (s *trackerServer) DeleteUser(ctx context.Context, req *protocol.DeleteUserRequest) (*emptypb.Empty, error) {
+	if userId, ok := interceptor.AuthenticatedUser(ctx); ok && userId != req.Id {
+		return nil, status.Error(codes.PermissionDenied, "users can only delete themselves")
+	}
+	if err := s.users.Delete(req.Id); err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *trackerServer) GetProject(ctx context.Context, req *protocol.GetProjectRequest) (*protocol.Project, error) {
+	p, err := s.projects.Project(req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return projectMessage(p), nil
+}
+
+func (s *trackerServer) ListProjects(ctx context.Context, req *protocol.ListProjectsRequest) (*protocol.ListProjectsResponse, error) {
+	projects, err := s.projects.Projects()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	start, end, next, err := page(len(projects), req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	resp := &protocol.ListProjectsResponse{NextPageToken: next}
+	for _, p := range projects[start:end] {
+		resp.Projects = append(resp.Projects, projectMessage(p))
+	}
+	return resp, nil
+}
+
+func (s *trackerServer) CreateProject(ctx context.Context, req *protocol.CreateProjectRequest) (*protocol.Project, error) {
+	if req.Project == nil {
+		return nil, status.Error(codes.InvalidArgument, "project is required")
+	}
+	p := &domain.Project{Name: req.Project.Name, OwnerId: req.Project.OwnerId, Description: req.Project.Description}
+	if err := s.projects.Create(p); err != nil {
+		return nil, statusError(err)
+	}
+	return projectMessage(p), nil
+}
+
+func (s *trackerServer) UpdateProject(ctx context.Context, req *protocol.UpdateProjectRequest) (*protocol.Project, error) {
+	if req.Project == nil {
+		return nil, status.Error(codes.InvalidArgument, "project is required")
+	}
+	p, err := s.projects.Project(req.Project.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if err := applyMask(req.UpdateMask, projectSetters(p, req.Project)); err != nil {
+		return nil, err
+	}
+	if err := s.projects.Update(p); err != nil {
+		return nil, statusError(err)
+	}
+	return projectMessage(p), nil
+}
+
+func (s *trackerServer) DeleteProject(ctx context.Context, req *protocol.DeleteProjectRequest) (*emptypb.Empty, error) {
+	if err := s.projects.Delete(req.Id); err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *trackerServer) GetIssue(ctx context.Context, req *protocol.GetIssueRequest) (*protocol.Issue, error) {
+	i, err := s.issues.Issue(req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return issueMessage(i), nil
+}
+
+// ListIssues runs the query when there is one and filters the issues otherwise.
+func (s *trackerServer) ListIssues(ctx context.Context, req *protocol.ListIssuesRequest) (*protocol.ListIssuesResponse, error) {
+	var issues []*domain.Issue
+	var err error
+	if req.Query != "" {
+		issues, err = s.issues.Search(req.Query, req.UserId)
+	} else {
+		issues, err = s.issues.Issues(domain.IssueFilter{
+			ProjectId: req.ProjectId,
+			SprintId:  req.SprintId,
+			Labels:    req.Labels,
+		})
+	}
+	if err != nil {
+		return nil, statusError(err)
+	}
+	start, end, next, err := page(len(issues), req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	resp := &protocol.ListIssuesResponse{NextPageToken: next}
+	for _, i := range issues[start:end] {
+		resp.Issues = append(resp.Issues, issueMessage(i))
+	}
+	return resp, nil
+}
+
+func (s *trackerServer) CreateIssue(ctx context.Context, req *protocol.CreateIssueRequest) (*protocol.Issue, error) {
+	if req.Issue == nil {
+		return nil, status.Error(codes.InvalidArgument, "issue is required")
+	}
+	i := &domain.Issue{}
+	if err := applyMask(nil, issueSetters(i, req.Issue)); err != nil {
+		return nil, err
+	}
+	if err := s.issues.Create(i); err != nil {
+		return nil, statusError(err)
+	}
+	return s.GetIssue(ctx, &protocol.GetIssueRequest{Id: i.Id})
+}
+
+func (s *trackerServer) UpdateIssue(ctx context.Context, req *protocol.UpdateIssueRequest) (*protocol.Issue, error) {
+	if req.Issue == nil {
+		return nil, status.Error(codes.InvalidArgument, "issue is required")
+	}
+	i, err := s.issues.Issue(req.Issue.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if err := applyMask(req.UpdateMask, issueSetters(i, req.Issue)); err != nil {
+		return nil, err
+	}
+	if err := s.issues.Update(i); err != nil {
+		return nil, statusError(err)
+	}
+	return s.GetIssue(ctx, &protocol.GetIssueRequest{Id: i.Id})
+}
+
+func (s *trackerServer) DeleteIssue(ctx context.Context, req *protocol.DeleteIssueRequest) (*emptypb.Empty, error) {
+	if err := s.issues.Delete(req.Id); err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// WatchIssues tails the append-only event log, so it also streams the changes
+// made by the tracker or any other process sharing the database. Without an
+// after_event_id it starts at the end of the log instead of replaying it.
+func (s *trackerServer) WatchIssues(req *protocol.WatchIssuesRequest, stream protocol.IssueTracker_WatchIssuesServer) error {
+	watched := make(map[int64]bool, len(req.IssueIds))
+	for _, id := range req.IssueIds {
+		watched[id] = true
+	}
+	after := req.AfterEventId
+	if after == 0 {
+		var err error
+		if after, err = s.activity.LastId(); err != nil {
+			return statusError(err)
+		}
+	}
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+	for {
+		events, err := s.activity.Since(after, watchBatch)
+		if err != nil {
+			return statusError(err)
+		}
+		for _, e := range events {
+			after = e.Id
+			if len(watched) > 0 && !watched[e.IssueId] {
+				continue
+			}
+			if err := stream.Send(issueEventMessage(e)); err != nil {
+				return err
+			}
+		}
+		if len(events) == watchBatch {
+			continue
+		}
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
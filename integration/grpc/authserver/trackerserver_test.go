@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+	"github.com/pwera/ddd/interceptor"
+	"github.com/pwera/ddd/persistence/blob"
+	"github.com/pwera/ddd/persistence/db"
+	"github.com/pwera/ddd/protocol/protocol"
+	"github.com/pwera/ddd/tracker"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// userTokens lets the "user-<id>" bearer tokens in as that user.
+type userTokens struct{}
+
+func (userTokens) Verify(token string) (*domain.AccessToken, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(token, "user-"), 10, 64)
+	if err != nil {
+		return nil, domain.ErrUnauthorized
+	}
+	return &domain.AccessToken{UserId: id, ExpiresAt: time.Now().Add(time.Minute)}, nil
+}
+
+// as authenticates the calls made with the context as the user.
+func as(userId int64) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", fmt.Sprintf("Bearer user-%d", userId))
+}
+
+// newTrackerClient serves the tracker RPCs over an in-memory connection.
+func newTrackerClient(t *testing.T) (protocol.IssueTrackerClient, tracker.Services) {
+	conn, err := db.Open(db.DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	store, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	services := tracker.NewServices(conn, db.NewUserRepository(conn), store, domain.DefaultSLA())
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(interceptor.Config{Validate: true, Verifier: userTokens{}}.ServerOptions()...)
+	protocol.RegisterIssueTrackerServer(server, newTrackerServer(services, 10*time.Millisecond))
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	dial, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dial.Close() })
+	return protocol.NewIssueTrackerClient(dial), services
+}
+
+func TestTrackerServer_Users(t *testing.T) {
+	client, services := newTrackerClient(t)
+	ann, err := services.Users.Register("Ann", "ann@example.com", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := services.Users.Register("Bob", "bob@example.com", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.CreateUser(as(ann.Id), &protocol.CreateUserRequest{User: &protocol.User{Name: "Eve", Email: "eve@example.com"}}); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("expected users without a password to be refused but got %v", err)
+	}
+	if _, err := client.UpdateUser(as(ann.Id), &protocol.UpdateUserRequest{User: &protocol.User{Id: bob.Id, Email: "ann+bob@example.com"}}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected ann not to change bob but got %v", err)
+	}
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"name"}}
+	updated, err := client.UpdateUser(as(ann.Id), &protocol.UpdateUserRequest{User: &protocol.User{Id: ann.Id, Name: "Anne", Email: "ignored@example.com"}, UpdateMask: mask})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "Anne" || updated.Email != "ann@example.com" {
+		t.Fatalf("expected only the masked name to change but got %+v", updated)
+	}
+	stored, err := services.Users.User(ann.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.PasswordHash != ann.PasswordHash {
+		t.Fatal("expected the update to keep the password")
+	}
+	mask = &fieldmaskpb.FieldMask{Paths: []string{"password"}}
+	if _, err := client.UpdateUser(as(ann.Id), &protocol.UpdateUserRequest{User: &protocol.User{Id: ann.Id}, UpdateMask: mask}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected an unknown field to be refused but got %v", err)
+	}
+
+	first, err := client.ListUsers(as(ann.Id), &protocol.ListUsersRequest{PageSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Users) != 1 || first.NextPageToken == "" {
+		t.Fatalf("expected a page of one user and a next page but got %+v", first)
+	}
+	second, err := client.ListUsers(as(ann.Id), &protocol.ListUsersRequest{PageSize: 1, PageToken: first.NextPageToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Users) != 1 || second.Users[0].Id != bob.Id || second.NextPageToken != "" {
+		t.Fatalf("expected bob on the last page but got %+v", second)
+	}
+	if _, err := client.GetUser(context.Background(), &protocol.GetUserRequest{Id: ann.Id}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected a call without a token to be refused but got %v", err)
+	}
+
+	if _, err := client.DeleteUser(as(ann.Id), &protocol.DeleteUserRequest{Id: bob.Id}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected ann not to delete bob but got %v", err)
+	}
+	if _, err := services.Users.User(bob.Id); err != nil {
+		t.Fatalf("expected bob to be kept but got %v", err)
+	}
+	if _, err := client.DeleteUser(as(bob.Id), &protocol.DeleteUserRequest{Id: bob.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetUser(as(ann.Id), &protocol.GetUserRequest{Id: bob.Id}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected bob to be gone but got %v", err)
+	}
+}
+
+func TestTrackerServer_ProjectsAndIssues(t *testing.T) {
+	client, services := newTrackerClient(t)
+	ctx := as(1)
+
+	project, err := client.CreateProject(ctx, &protocol.CreateProjectRequest{Project: &protocol.Project{Name: "Tracker", Description: "issues"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"description"}}
+	project, err = client.UpdateProject(ctx, &protocol.UpdateProjectRequest{Project: &protocol.Project{Id: project.Id, Name: "ignored", Description: "bugs"}, UpdateMask: mask})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.Name != "Tracker" || project.Description != "bugs" {
+		t.Fatalf("expected only the masked description to change but got %+v", project)
+	}
+
+	issue, err := client.CreateIssue(ctx, &protocol.CreateIssueRequest{Issue: &protocol.Issue{Title: "Bug", ProjectId: project.Id, Priority: protocol.Priority_PRIORITY_LOW}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Id == 0 || issue.Status == "" || issue.CreatedAt == nil {
+		t.Fatalf("expected a stored issue in the initial state but got %+v", issue)
+	}
+	mask = &fieldmaskpb.FieldMask{Paths: []string{"title"}}
+	issue, err = client.UpdateIssue(ctx, &protocol.UpdateIssueRequest{Issue: &protocol.Issue{Id: issue.Id, Title: "Crash", Priority: protocol.Priority_PRIORITY_HIGH}, UpdateMask: mask})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Title != "Crash" || issue.Priority != protocol.Priority_PRIORITY_LOW {
+		t.Fatalf("expected only the masked title to change but got %+v", issue)
+	}
+	list, err := client.ListIssues(ctx, &protocol.ListIssuesRequest{ProjectId: project.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Issues) != 1 || list.Issues[0].Id != issue.Id {
+		t.Fatalf("expected the issue of the project but got %+v", list.Issues)
+	}
+	if _, err := client.DeleteIssue(ctx, &protocol.DeleteIssueRequest{Id: issue.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetIssue(ctx, &protocol.GetIssueRequest{Id: issue.Id}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected the deleted issue to be gone but got %v", err)
+	}
+
+	// the changes went through the same event log as the tracker's
+	events, err := services.Activity.Since(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.EventType{domain.EventIssueCreated, domain.EventIssueFieldChanged, domain.EventIssueDeleted}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events but got %+v", len(want), events)
+	}
+	for n, e := range events {
+		if e.Type != want[n] || e.IssueId != issue.Id {
+			t.Fatalf("expected a %s event of issue %d but got %+v", want[n], issue.Id, e)
+		}
+	}
+
+	if _, err := client.DeleteProject(ctx, &protocol.DeleteProjectRequest{Id: project.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetProject(ctx, &protocol.GetProjectRequest{Id: project.Id}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected the deleted project to be gone but got %v", err)
+	}
+}
+
+func TestTrackerServer_WatchIssues(t *testing.T) {
+	client, services := newTrackerClient(t)
+	ctx, cancel := context.WithTimeout(as(1), 5*time.Second)
+	defer cancel()
+
+	project, err := client.CreateProject(ctx, &protocol.CreateProjectRequest{Project: &protocol.Project{Name: "Tracker"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := func(title string) *protocol.Issue {
+		i, err := client.CreateIssue(ctx, &protocol.CreateIssueRequest{Issue: &protocol.Issue{Title: title, ProjectId: project.Id, Priority: protocol.Priority_PRIORITY_LOW}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return i
+	}
+	watched, other := create("Watched"), create("Other")
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"title"}}
+	if _, err := client.UpdateIssue(ctx, &protocol.UpdateIssueRequest{Issue: &protocol.Issue{Id: watched.Id, Title: "Renamed"}, UpdateMask: mask}); err != nil {
+		t.Fatal(err)
+	}
+	last, err := services.Activity.LastId()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// from an event on, only the events of the watched issues
+	stream, err := client.WatchIssues(ctx, &protocol.WatchIssuesRequest{IssueIds: []int64{watched.Id}, AfterEventId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Id != last || e.IssueId != watched.Id || e.Field != "title" {
+		t.Fatalf("expected the rename of the watched issue but got %+v", e)
+	}
+
+	// without an event, only the events recorded after the call
+	stream, err = client.WatchIssues(ctx, &protocol.WatchIssuesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		// the stream may start after the first of these changes
+		for n := 0; ; n++ {
+			select {
+			case <-done:
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+			client.UpdateIssue(ctx, &protocol.UpdateIssueRequest{Issue: &protocol.Issue{Id: other.Id, Title: fmt.Sprintf("Other %d", n)}, UpdateMask: mask})
+		}
+	}()
+	e, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Id <= last || e.IssueId != other.Id {
+		t.Fatalf("expected a change made after the call but got %+v", e)
+	}
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type ActivityController struct {
+	BaseController
+	ActivityService domain.ActivityService
+}
+
+// List returns the activity feed of the {id} issue.
+func (c ActivityController) List(w http.ResponseWriter, r *http.Request) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	events, err := c.ActivityService.Activity(issueId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(events, w)
+}
+
+// Check rebuilds the {id} issue from its events and lists how it differs from the stored one.
+func (c ActivityController) Check(w http.ResponseWriter, r *http.Request) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	consistency, err := c.ActivityService.Check(issueId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(consistency, w)
+}
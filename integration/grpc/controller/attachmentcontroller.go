@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/pwera/ddd/domain"
+)
+
+// multipartOverhead is how much larger than the attachment a request body
+// may be for the multipart framing and the other form fields.
+const multipartOverhead = 64 << 10
+
+type AttachmentController struct {
+	BaseController
+	AttachmentService domain.AttachmentService
+	// MaxSize is the largest attachment accepted, zero lets any body through.
+	MaxSize int64
+}
+
+// ids returns the {id} of the issue and the {attachmentId} route variables.
+func (c AttachmentController) ids(r *http.Request) (int64, int64, error) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	attachmentId, err := c.PathVar(r, "attachmentId")
+	if err != nil {
+		return 0, 0, err
+	}
+	return issueId, attachmentId, nil
+}
+
+func (c AttachmentController) List(w http.ResponseWriter, r *http.Request) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	attachments, err := c.AttachmentService.Attachments(issueId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(attachments, w)
+}
+
+// Create streams the "file" part of a multipart/form-data body to the blob store
+// without buffering it.
+func (c AttachmentController) Create(w http.ResponseWriter, r *http.Request) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if c.MaxSize > 0 {
+		max := c.MaxSize + multipartOverhead
+		r.Body = &bodyLimit{ReadCloser: http.MaxBytesReader(w, r.Body, max), max: max}
+	}
+	parts, err := r.MultipartReader()
+	if err != nil {
+		c.WriteError(w, domain.NewValidationError("expected a multipart/form-data body"))
+		return
+	}
+	for {
+		part, err := parts.NextPart()
+		if errors.Is(err, io.EOF) {
+			c.WriteError(w, domain.NewValidationError("the file part is required"))
+			return
+		}
+		if errors.Is(err, domain.ErrTooLarge) {
+			c.WriteError(w, err)
+			return
+		}
+		if err != nil {
+			c.WriteError(w, domain.NewValidationError("invalid multipart body: "+err.Error()))
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+		attachment, err := c.AttachmentService.Upload(issueId, part.FileName(), part)
+		part.Close()
+		if err != nil {
+			c.WriteError(w, err)
+			return
+		}
+		c.WriteJSON(w, http.StatusCreated, attachment)
+		return
+	}
+}
+
+// Download serves the content of the attachment, ranges and conditional
+// requests are handled by http.ServeContent.
+func (c AttachmentController) Download(w http.ResponseWriter, r *http.Request) {
+	issueId, attachmentId, err := c.ids(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	attachment, content, err := c.AttachmentService.Open(issueId, attachmentId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	defer content.Close()
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+	w.Header().Set("ETag", strconv.Quote(attachment.Digest))
+	http.ServeContent(w, r, attachment.Name, attachment.CreatedAt, content)
+}
+
+func (c AttachmentController) Delete(w http.ResponseWriter, r *http.Request) {
+	issueId, attachmentId, err := c.ids(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := c.AttachmentService.Delete(issueId, attachmentId); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bodyLimit turns the failure of http.MaxBytesReader into domain.ErrTooLarge.
+type bodyLimit struct {
+	io.ReadCloser
+	read int64
+	max  int64
+}
+
+func (b *bodyLimit) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if err != nil && err != io.EOF && b.read >= b.max {
+		return n, fmt.Errorf("request body is larger than %d bytes: %w", b.max, domain.ErrTooLarge)
+	}
+	return n, err
+}
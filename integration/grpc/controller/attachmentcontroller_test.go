@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pwera/ddd/application"
+	"github.com/pwera/ddd/domain"
+	"github.com/pwera/ddd/persistence/blob"
+	"github.com/pwera/ddd/persistence/db"
+)
+
+// newAttachmentRouter serves the attachment routes of an issue accepting
+// attachments of at most maxSize bytes.
+func newAttachmentRouter(t *testing.T, maxSize int64) (http.Handler, *domain.Issue) {
+	conn, err := db.Open(db.DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	store, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := db.NewIssueRepository(conn)
+	now := time.Now().UTC()
+	issue := &domain.Issue{Title: "Bug", Priority: domain.PriorityLow, Status: domain.StatusOpen, CreatedAt: now, UpdatedAt: now}
+	if err := issues.Create(issue); err != nil {
+		t.Fatal(err)
+	}
+
+	c := AttachmentController{
+		AttachmentService: application.AttachmentService{
+			AttachmentRepository: db.NewAttachmentRepository(conn),
+			IssueRepository:      issues,
+			BlobStore:            store,
+			MaxSize:              maxSize,
+			AllowedTypes:         application.DefaultAttachmentTypes,
+		},
+		MaxSize: maxSize,
+	}
+	r := mux.NewRouter()
+	r.HandleFunc("/api/issues/{id:[0-9]+}/attachments", c.Create).Methods(http.MethodPost)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/attachments/{attachmentId:[0-9]+}", c.Download).Methods(http.MethodGet)
+	return r, issue
+}
+
+// multipartBody writes the fields in order, the one named file as a file part.
+func multipartBody(t *testing.T, fields ...[2]string) (io.Reader, string) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for _, f := range fields {
+		var part io.Writer
+		var err error
+		if f[0] == "file" {
+			part, err = w.CreateFormFile("file", "notes.txt")
+		} else {
+			part, err = w.CreateFormField(f[0])
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(part, f[1])
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body, w.FormDataContentType()
+}
+
+func upload(t *testing.T, h http.Handler, issueId int64, fields ...[2]string) *httptest.ResponseRecorder {
+	body, contentType := multipartBody(t, fields...)
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/issues/%d/attachments", issueId), body)
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAttachmentController_UploadAndDownloadRange(t *testing.T) {
+	h, issue := newAttachmentRouter(t, 1<<10)
+
+	rec := upload(t, h, issue.Id, [2]string{"comment", "ignored"}, [2]string{"file", "hello attachments"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 but got %d: %s", rec.Code, rec.Body)
+	}
+	var a domain.Attachment
+	if err := json.NewDecoder(rec.Body).Decode(&a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Name != "notes.txt" || a.Size != 17 || !strings.HasPrefix(a.ContentType, "text/plain") {
+		t.Fatalf("unexpected attachment %+v", a)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/issues/%d/attachments/%d", issue.Id, a.Id), nil)
+	req.Header.Set("Range", "bytes=6-9")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "atta" {
+		t.Fatalf("expected 206 with atta but got %d: %q", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes 6-9/17" {
+		t.Fatalf("expected Content-Range bytes 6-9/17 but got %q", got)
+	}
+}
+
+func TestAttachmentController_Refusals(t *testing.T) {
+	h, issue := newAttachmentRouter(t, 16)
+
+	tests := []struct {
+		name   string
+		fields [][2]string
+		code   int
+	}{
+		{"attachment over the limit", [][2]string{{"file", strings.Repeat("a", 17)}}, http.StatusRequestEntityTooLarge},
+		{"body over the limit before the file", [][2]string{{"comment", strings.Repeat("a", multipartOverhead)}, {"file", "small"}}, http.StatusRequestEntityTooLarge},
+		{"unsupported content", [][2]string{{"file", "\x7fELF\x02\x01\x01\x00"}}, http.StatusUnsupportedMediaType},
+		{"no file part", [][2]string{{"comment", "no file"}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := upload(t, h, issue.Id, tt.fields...); rec.Code != tt.code {
+				t.Fatalf("expected %d but got %d: %s", tt.code, rec.Code, rec.Body)
+			}
+		})
+	}
+}
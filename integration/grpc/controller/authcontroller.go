@@ -1,7 +1,6 @@
 package controller
 
 import (
-	"fmt"
 	"github.com/pwera/ddd/protocol/protocol"
 	"golang.org/x/net/context"
 	"net/http"
@@ -13,20 +12,30 @@ type AuthorizationController struct {
 	Client protocol.UserClient
 }
 
+type registration struct {
+	Name     string
+	Email    string
+	Password string
+}
+
+// Register creates the user through the auth server, which owns the passwords.
 func (ac AuthorizationController) Register(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "invalid method", http.StatusBadRequest)
+	var reg registration
+	if err := ac.ReadJSON(r, &reg); err != nil {
+		ac.WriteError(w, err)
 		return
 	}
-	ctx, cancelFunc := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancelFunc := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancelFunc()
 
-	user, err := ac.Client.SubmitNewUser(ctx, &protocol.NewUserRequest{
-		Email: "email",
+	resp, err := ac.Client.SubmitNewUser(ctx, &protocol.NewUserRequest{
+		Name:     reg.Name,
+		Email:    reg.Email,
+		Password: reg.Password,
 	})
 	if err != nil {
-		http.Error(w, "problem with SubmitNewUser", http.StatusBadRequest)
+		ac.WriteError(w, err)
 		return
 	}
-	fmt.Println(user)
+	ac.WriteJSON(w, http.StatusCreated, struct{ Id int64 }{resp.UserId})
 }
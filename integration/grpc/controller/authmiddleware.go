@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pwera/ddd/domain"
+	"golang.org/x/net/context"
+)
+
+type TokenVerifier interface {
+	Verify(token string) (*domain.AccessToken, error)
+}
+
+type authenticatedUserKey struct{}
+
+// Authenticator lets through the requests carrying a valid bearer token and
+// the requests to the Public paths.
+type Authenticator struct {
+	BaseController
+	Verifier TokenVerifier
+	Public   map[string]bool
+}
+
+func (a Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.Public[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		header := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(header, "Bearer ")
+		if token == header {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			a.WriteError(w, fmt.Errorf("a bearer token is required: %w", domain.ErrUnauthorized))
+			return
+		}
+		access, err := a.Verifier.Verify(token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			a.WriteError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authenticatedUserKey{}, access.UserId)))
+	})
+}
+
+// AuthenticatedUser returns the user whose token let the request through.
+func AuthenticatedUser(r *http.Request) (int64, bool) {
+	userId, ok := r.Context().Value(authenticatedUserKey{}).(int64)
+	return userId, ok
+}
@@ -1,19 +1,91 @@
 package controller
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pwera/ddd/domain"
+	"google.golang.org/grpc/status"
 )
 
 type BaseController struct{}
 
 func (bc BaseController) MarshalAndWriteHeaders(data interface{}, w http.ResponseWriter) {
+	bc.WriteJSON(w, http.StatusOK, data)
+}
+
+func (bc BaseController) WriteJSON(w http.ResponseWriter, status int, data interface{}) {
 	usersJson, err := json.Marshal(data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(status)
 	w.Write(usersJson)
 }
+
+// WriteError maps the domain errors and the gRPC status errors of the clients
+// to the HTTP status codes.
+func (bc BaseController) WriteError(w http.ResponseWriter, err error) {
+	if s, ok := status.FromError(err); ok {
+		http.Error(w, s.Message(), runtime.HTTPStatusFromCode(s.Code()))
+		return
+	}
+	var validation *domain.ValidationError
+	switch {
+	case errors.As(err, &validation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrNotFound), errors.Is(err, sql.ErrNoRows):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrUnauthorized):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, domain.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, domain.ErrUnsupportedType):
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (bc BaseController) ReadJSON(r *http.Request, data interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(data); err != nil {
+		return domain.NewValidationError("invalid request body: " + err.Error())
+	}
+	return nil
+}
+
+// PathId returns the {id} route variable.
+func (bc BaseController) PathId(r *http.Request) (int64, error) {
+	return bc.PathVar(r, "id")
+}
+
+// PathVar returns a numeric route variable.
+func (bc BaseController) PathVar(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)[name], 10, 64)
+	if err != nil {
+		return 0, domain.NewValidationError("invalid " + name)
+	}
+	return id, nil
+}
+
+// QueryInt returns a numeric query parameter or def when it is missing.
+func (bc BaseController) QueryInt(r *http.Request, name string, def int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, domain.NewValidationError("invalid " + name)
+	}
+	return n, nil
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type CommentController struct {
+	BaseController
+	CommentService domain.CommentService
+}
+
+type commentEdit struct {
+	Body string
+}
+
+// ids returns the {id} of the issue and the {commentId} route variables.
+func (c CommentController) ids(r *http.Request) (int64, int64, error) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	commentId, err := c.PathVar(r, "commentId")
+	if err != nil {
+		return 0, 0, err
+	}
+	return issueId, commentId, nil
+}
+
+// List takes the limit and offset query parameters.
+func (c CommentController) List(w http.ResponseWriter, r *http.Request) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	limit, err := c.QueryInt(r, "limit", 0)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	offset, err := c.QueryInt(r, "offset", 0)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	page, err := c.CommentService.Comments(issueId, limit, offset)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(page, w)
+}
+
+func (c CommentController) Create(w http.ResponseWriter, r *http.Request) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var comment domain.Comment
+	if err := c.ReadJSON(r, &comment); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	comment.Id = 0
+	comment.IssueId = issueId
+	if err := c.CommentService.Create(&comment); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.WriteJSON(w, http.StatusCreated, comment)
+}
+
+func (c CommentController) Update(w http.ResponseWriter, r *http.Request) {
+	issueId, commentId, err := c.ids(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var edit commentEdit
+	if err := c.ReadJSON(r, &edit); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	comment, err := c.CommentService.Edit(issueId, commentId, edit.Body)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(comment, w)
+}
+
+func (c CommentController) Delete(w http.ResponseWriter, r *http.Request) {
+	issueId, commentId, err := c.ids(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := c.CommentService.Delete(issueId, commentId); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c CommentController) Revisions(w http.ResponseWriter, r *http.Request) {
+	issueId, commentId, err := c.ids(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	revisions, err := c.CommentService.Revisions(issueId, commentId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(revisions, w)
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type FieldController struct {
+	BaseController
+	FieldService domain.FieldService
+}
+
+func (c FieldController) List(w http.ResponseWriter, r *http.Request) {
+	projectId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	fields, err := c.FieldService.Fields(projectId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(fields, w)
+}
+
+func (c FieldController) Create(w http.ResponseWriter, r *http.Request) {
+	projectId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var field domain.FieldDefinition
+	if err := c.ReadJSON(r, &field); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	field.Id = 0
+	field.ProjectId = projectId
+	if err := c.FieldService.Define(&field); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.WriteJSON(w, http.StatusCreated, field)
+}
+
+func (c FieldController) Delete(w http.ResponseWriter, r *http.Request) {
+	projectId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	fieldId, err := c.PathVar(r, "fieldId")
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := c.FieldService.Delete(projectId, fieldId); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Set takes an object of field names to values, null removes a field.
+func (c FieldController) Set(w http.ResponseWriter, r *http.Request) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var values map[string]interface{}
+	if err := c.ReadJSON(r, &values); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	fields, err := c.FieldService.Set(issueId, values)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(fields, w)
+}
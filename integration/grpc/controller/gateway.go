@@ -0,0 +1,15 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/status"
+)
+
+// GatewayError writes the errors of the gRPC gateway like WriteError writes the
+// errors of the controllers: the message as text with the matching HTTP status.
+func GatewayError(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	BaseController{}.WriteError(w, status.Convert(err).Err())
+}
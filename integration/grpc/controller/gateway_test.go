@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pwera/ddd/protocol/protocol"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// failingTracker fails GetIssue with the status code numbered like the issue.
+type failingTracker struct {
+	protocol.UnimplementedIssueTrackerServer
+}
+
+func (failingTracker) GetIssue(ctx context.Context, req *protocol.GetIssueRequest) (*protocol.Issue, error) {
+	return nil, status.Errorf(codes.Code(req.Id), "issue %d failed", req.Id)
+}
+
+func TestGatewayError_TranslatesStatusCodes(t *testing.T) {
+	gateway := runtime.NewServeMux(runtime.WithErrorHandler(GatewayError))
+	if err := protocol.RegisterIssueTrackerHandlerServer(context.Background(), gateway, failingTracker{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for code, want := range map[codes.Code]int{
+		codes.InvalidArgument:    http.StatusBadRequest,
+		codes.Unauthenticated:    http.StatusUnauthorized,
+		codes.PermissionDenied:   http.StatusForbidden,
+		codes.NotFound:           http.StatusNotFound,
+		codes.AlreadyExists:      http.StatusConflict,
+		codes.FailedPrecondition: http.StatusBadRequest,
+		codes.Unimplemented:      http.StatusNotImplemented,
+		codes.Unavailable:        http.StatusServiceUnavailable,
+		codes.Internal:           http.StatusInternalServerError,
+	} {
+		id := strconv.Itoa(int(code))
+		rec := httptest.NewRecorder()
+		gateway.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/issues/"+id, nil))
+		if rec.Code != want {
+			t.Fatalf("expected %s to be %d but got %d", code, want, rec.Code)
+		}
+		if body := strings.TrimSpace(rec.Body.String()); body != "issue "+id+" failed" {
+			t.Fatalf("expected the message of the status as text but got %q", body)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+			t.Fatalf("expected a text error like the controllers' but got %q", ct)
+		}
+	}
+
+	// the gateway fails the request itself before calling the server
+	rec := httptest.NewRecorder()
+	gateway.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/issues/abc", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected an invalid id to be a bad request but got %d", rec.Code)
+	}
+}
+
+func TestGatewayError_WritesOtherErrorsAsInternal(t *testing.T) {
+	rec := httptest.NewRecorder()
+	GatewayError(context.Background(), nil, nil, rec, httptest.NewRequest(http.MethodGet, "/v1/issues/1", nil), errors.New("broken pipe"))
+	if rec.Code != http.StatusInternalServerError || strings.TrimSpace(rec.Body.String()) != "broken pipe" {
+		t.Fatalf("expected a 500 with the error but got %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -1,8 +1,10 @@
 package controller
 
 import (
-	"github.com/pwera/ddd/domain"
 	"net/http"
+	"strings"
+
+	"github.com/pwera/ddd/domain"
 )
 
 type IssueController struct {
@@ -10,15 +12,221 @@ type IssueController struct {
 	IssueService domain.IssueService
 }
 
+// issuePatch holds the fields a PATCH request may change, nil means unchanged.
+type issuePatch struct {
+	Title       *string
+	Description *string
+	ProjectId   *int64
+	OwnerId     *int64
+	Priority    *domain.Priority
+	SprintId    *int64
+	Points      *float64
+}
+
+type transitionRequest struct {
+	To     domain.Status
+	UserId int64
+}
+
+// List filters with ?project=3&label=bug&field.Severity=high, labels and fields
+// may repeat and all of them have to match. A ?q= query replaces the filter,
+// ?user= is the user "me" stands for in it.
 func (c IssueController) List(w http.ResponseWriter, r *http.Request) {
-	issue, err := c.IssueService.Issues()
+	if q := r.URL.Query().Get("q"); q != "" {
+		c.search(w, r, q)
+		return
+	}
+	filter, err := c.filter(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	issue, err := c.IssueService.Issues(filter)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(issue, w)
+}
+
+func (c IssueController) search(w http.ResponseWriter, r *http.Request, q string) {
+	userId, err := c.QueryInt(r, "user", 0)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	issues, err := c.IssueService.Search(q, int64(userId))
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(issues, w)
+}
+
+func (c IssueController) filter(r *http.Request) (domain.IssueFilter, error) {
+	projectId, err := c.QueryInt(r, "project", 0)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return domain.IssueFilter{}, err
+	}
+	filter := domain.IssueFilter{
+		ProjectId: int64(projectId),
+		Labels:    r.URL.Query()["label"],
+		Fields:    map[string]string{},
+	}
+	for key, values := range r.URL.Query() {
+		if name := strings.TrimPrefix(key, "field."); name != key && len(values) > 0 {
+			filter.Fields[name] = values[0]
+		}
+	}
+	return filter, nil
+}
+
+func (c IssueController) Show(w http.ResponseWriter, r *http.Request) {
+	id, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	issue, err := c.IssueService.Issue(id)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(issue, w)
+}
+
+func (c IssueController) Create(w http.ResponseWriter, r *http.Request) {
+	var issue domain.Issue
+	if err := c.ReadJSON(r, &issue); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	issue.Id = 0
+	if err := c.IssueService.Create(&issue); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.WriteJSON(w, http.StatusCreated, issue)
+}
+
+// Update replaces the whole issue.
+func (c IssueController) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var issue domain.Issue
+	if err := c.ReadJSON(r, &issue); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	issue.Id = id
+	if err := c.IssueService.Update(&issue); err != nil {
+		c.WriteError(w, err)
 		return
 	}
 	c.MarshalAndWriteHeaders(issue, w)
 }
 
-func (c IssueController) Show(w http.ResponseWriter, r *http.Request)   {}
-func (c IssueController) Create(w http.ResponseWriter, r *http.Request) {}
-func (c IssueController) Delete(w http.ResponseWriter, r *http.Request) {}
+// Patch only changes the fields present in the body.
+func (c IssueController) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var patch issuePatch
+	if err := c.ReadJSON(r, &patch); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	issue, err := c.IssueService.Issue(id)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if patch.Title != nil {
+		issue.Title = *patch.Title
+	}
+	if patch.Description != nil {
+		issue.Description = *patch.Description
+	}
+	if patch.ProjectId != nil {
+		issue.ProjectId = *patch.ProjectId
+	}
+	if patch.OwnerId != nil {
+		issue.OwnerId = *patch.OwnerId
+	}
+	if patch.Priority != nil {
+		issue.Priority = *patch.Priority
+	}
+	if patch.SprintId != nil {
+		issue.SprintId = *patch.SprintId
+	}
+	if patch.Points != nil {
+		issue.Points = *patch.Points
+	}
+	if err := c.IssueService.Update(issue); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(issue, w)
+}
+
+func (c IssueController) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := c.IssueService.Delete(id); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Transition moves the issue to the state given in the body.
+func (c IssueController) Transition(w http.ResponseWriter, r *http.Request) {
+	id, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var req transitionRequest
+	if err := c.ReadJSON(r, &req); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	issue, err := c.IssueService.Transition(id, req.To, req.UserId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(issue, w)
+}
+
+// Breaching lists the open issues past the SLA of their priority.
+func (c IssueController) Breaching(w http.ResponseWriter, r *http.Request) {
+	issues, err := c.IssueService.Breaching()
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(issues, w)
+}
+
+func (c IssueController) History(w http.ResponseWriter, r *http.Request) {
+	id, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	history, err := c.IssueService.History(id)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(history, w)
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/pwera/ddd/auth"
+	"github.com/pwera/ddd/domain"
+)
+
+// KeyController publishes what other servers need to verify access tokens locally.
+type KeyController struct {
+	BaseController
+	Signer      *auth.Signer
+	AuthService domain.AuthService
+}
+
+func (c KeyController) KeySet(w http.ResponseWriter, r *http.Request) {
+	c.MarshalAndWriteHeaders(c.Signer.KeySet(), w)
+}
+
+func (c KeyController) Revocations(w http.ResponseWriter, r *http.Request) {
+	revoked, err := c.AuthService.Revoked()
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(revoked, w)
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type LabelController struct {
+	BaseController
+	LabelService domain.LabelService
+}
+
+func (c LabelController) List(w http.ResponseWriter, r *http.Request) {
+	projectId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	labels, err := c.LabelService.Labels(projectId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(labels, w)
+}
+
+func (c LabelController) Create(w http.ResponseWriter, r *http.Request) {
+	projectId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var label domain.Label
+	if err := c.ReadJSON(r, &label); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	label.Id = 0
+	label.ProjectId = projectId
+	if err := c.LabelService.Create(&label); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.WriteJSON(w, http.StatusCreated, label)
+}
+
+func (c LabelController) Delete(w http.ResponseWriter, r *http.Request) {
+	projectId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	labelId, err := c.PathVar(r, "labelId")
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := c.LabelService.Delete(projectId, labelId); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Attach puts the {labelId} label on the {id} issue.
+func (c LabelController) Attach(w http.ResponseWriter, r *http.Request) {
+	c.issueLabel(w, r, c.LabelService.Attach)
+}
+
+func (c LabelController) Detach(w http.ResponseWriter, r *http.Request) {
+	c.issueLabel(w, r, c.LabelService.Detach)
+}
+
+func (c LabelController) issueLabel(w http.ResponseWriter, r *http.Request, change func(issueId, labelId int64) error) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	labelId, err := c.PathVar(r, "labelId")
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := change(issueId, labelId); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
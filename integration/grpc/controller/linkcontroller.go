@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type LinkController struct {
+	BaseController
+	LinkService domain.LinkService
+}
+
+// linkRequest links the issue of the route to IssueId, Type may also be an
+// inverse like blocked-by or child-of.
+type linkRequest struct {
+	Type    string
+	IssueId int64
+}
+
+func (c LinkController) List(w http.ResponseWriter, r *http.Request) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	links, err := c.LinkService.Links(issueId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(links, w)
+}
+
+func (c LinkController) Create(w http.ResponseWriter, r *http.Request) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var req linkRequest
+	if err := c.ReadJSON(r, &req); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	link, err := c.LinkService.Link(issueId, req.IssueId, req.Type)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.WriteJSON(w, http.StatusCreated, link)
+}
+
+func (c LinkController) Delete(w http.ResponseWriter, r *http.Request) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	linkId, err := c.PathVar(r, "linkId")
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := c.LinkService.Unlink(issueId, linkId); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c LinkController) Blockers(w http.ResponseWriter, r *http.Request) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	blockers, err := c.LinkService.Blockers(issueId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(blockers, w)
+}
+
+// DependencyGraph answers with JSON, or with Graphviz DOT for ?format=dot.
+func (c LinkController) DependencyGraph(w http.ResponseWriter, r *http.Request) {
+	projectId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "dot" {
+		c.WriteError(w, domain.NewValidationError("format must be json or dot"))
+		return
+	}
+	graph, err := c.LinkService.DependencyGraph(projectId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if format == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		w.Write([]byte(graph.DOT()))
+		return
+	}
+	c.MarshalAndWriteHeaders(graph, w)
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type NotificationController struct {
+	BaseController
+	NotificationService domain.NotificationService
+}
+
+// List returns the inbox of the {id} user, ?unread=1 leaves out the read notifications.
+func (c NotificationController) List(w http.ResponseWriter, r *http.Request) {
+	userId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	unread, err := c.QueryInt(r, "unread", 0)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	notifications, err := c.NotificationService.Notifications(userId, unread != 0)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(notifications, w)
+}
+
+func (c NotificationController) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	notificationId, err := c.PathVar(r, "notificationId")
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := c.NotificationService.MarkRead(userId, notificationId); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c NotificationController) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := c.NotificationService.MarkAllRead(userId); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
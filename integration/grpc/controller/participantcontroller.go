@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type ParticipantController struct {
+	BaseController
+	ParticipantService domain.ParticipantService
+}
+
+// Assign makes the {userId} user an assignee of the {id} issue.
+func (c ParticipantController) Assign(w http.ResponseWriter, r *http.Request) {
+	c.issueUser(w, r, c.ParticipantService.Assign)
+}
+
+func (c ParticipantController) Unassign(w http.ResponseWriter, r *http.Request) {
+	c.issueUser(w, r, c.ParticipantService.Unassign)
+}
+
+func (c ParticipantController) Watch(w http.ResponseWriter, r *http.Request) {
+	c.issueUser(w, r, c.ParticipantService.Watch)
+}
+
+func (c ParticipantController) Unwatch(w http.ResponseWriter, r *http.Request) {
+	c.issueUser(w, r, c.ParticipantService.Unwatch)
+}
+
+func (c ParticipantController) issueUser(w http.ResponseWriter, r *http.Request, change func(issueId, userId int64) error) {
+	issueId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	userId, err := c.PathVar(r, "userId")
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := change(issueId, userId); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type ProjectController struct {
+	BaseController
+	ProjectService domain.ProjectService
+}
+
+func (c ProjectController) List(w http.ResponseWriter, r *http.Request) {
+	projects, err := c.ProjectService.Projects()
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(projects, w)
+}
+
+func (c ProjectController) Show(w http.ResponseWriter, r *http.Request) {
+	id, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	project, err := c.ProjectService.Project(id)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(project, w)
+}
+
+func (c ProjectController) Create(w http.ResponseWriter, r *http.Request) {
+	var project domain.Project
+	if err := c.ReadJSON(r, &project); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	project.Id = 0
+	if err := c.ProjectService.Create(&project); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.WriteJSON(w, http.StatusCreated, project)
+}
+
+func (c ProjectController) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := c.ProjectService.Delete(id); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c ProjectController) Workflow(w http.ResponseWriter, r *http.Request) {
+	id, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	workflow, err := c.ProjectService.Workflow(id)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(workflow, w)
+}
+
+func (c ProjectController) SetWorkflow(w http.ResponseWriter, r *http.Request) {
+	id, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var workflow domain.Workflow
+	if err := c.ReadJSON(r, &workflow); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	workflow.ProjectId = id
+	if err := c.ProjectService.SetWorkflow(&workflow); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(workflow, w)
+}
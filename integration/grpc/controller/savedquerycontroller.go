@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type SavedQueryController struct {
+	BaseController
+	SavedQueryService domain.SavedQueryService
+}
+
+// ids returns the {id} of the user and the {queryId} route variables.
+func (c SavedQueryController) ids(r *http.Request) (int64, int64, error) {
+	userId, err := c.PathId(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	queryId, err := c.PathVar(r, "queryId")
+	if err != nil {
+		return 0, 0, err
+	}
+	return userId, queryId, nil
+}
+
+func (c SavedQueryController) List(w http.ResponseWriter, r *http.Request) {
+	userId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	queries, err := c.SavedQueryService.Queries(userId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(queries, w)
+}
+
+func (c SavedQueryController) Create(w http.ResponseWriter, r *http.Request) {
+	userId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var query domain.SavedQuery
+	if err := c.ReadJSON(r, &query); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	query.Id = 0
+	query.UserId = userId
+	if err := c.SavedQueryService.Save(&query); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.WriteJSON(w, http.StatusCreated, query)
+}
+
+func (c SavedQueryController) Delete(w http.ResponseWriter, r *http.Request) {
+	userId, queryId, err := c.ids(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := c.SavedQueryService.Delete(userId, queryId); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Issues runs the saved query.
+func (c SavedQueryController) Issues(w http.ResponseWriter, r *http.Request) {
+	userId, queryId, err := c.ids(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	issues, err := c.SavedQueryService.Run(userId, queryId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(issues, w)
+}
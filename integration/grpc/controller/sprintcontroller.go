@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/pwera/ddd/domain"
+)
+
+type SprintController struct {
+	BaseController
+	SprintService domain.SprintService
+}
+
+// ids returns the {id} of the project and the {sprintId} route variables.
+func (c SprintController) ids(r *http.Request) (int64, int64, error) {
+	projectId, err := c.PathId(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	sprintId, err := c.PathVar(r, "sprintId")
+	if err != nil {
+		return 0, 0, err
+	}
+	return projectId, sprintId, nil
+}
+
+func (c SprintController) List(w http.ResponseWriter, r *http.Request) {
+	projectId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	sprints, err := c.SprintService.Sprints(projectId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(sprints, w)
+}
+
+func (c SprintController) Create(w http.ResponseWriter, r *http.Request) {
+	projectId, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var sprint domain.Sprint
+	if err := c.ReadJSON(r, &sprint); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	sprint.Id = 0
+	sprint.ProjectId = projectId
+	if err := c.SprintService.Create(&sprint); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.WriteJSON(w, http.StatusCreated, sprint)
+}
+
+func (c SprintController) Delete(w http.ResponseWriter, r *http.Request) {
+	projectId, sprintId, err := c.ids(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := c.SprintService.Delete(projectId, sprintId); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Report serves the burndown and burnup of the sprint.
+func (c SprintController) Report(w http.ResponseWriter, r *http.Request) {
+	projectId, sprintId, err := c.ids(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	report, err := c.SprintService.Report(projectId, sprintId)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(report, w)
+}
@@ -20,6 +20,62 @@ func (c UserController) List(w http.ResponseWriter, r *http.Request) {
 	c.MarshalAndWriteHeaders(users, w)
 }
 
-func (c UserController) Show(w http.ResponseWriter, r *http.Request)   {}
-func (c UserController) Create(w http.ResponseWriter, r *http.Request) {}
-func (c UserController) Delete(w http.ResponseWriter, r *http.Request) {}
+func (c UserController) Show(w http.ResponseWriter, r *http.Request) {
+	id, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	user, err := c.UserService.User(id)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(user, w)
+}
+
+func (c UserController) Create(w http.ResponseWriter, r *http.Request) {
+	var user domain.User
+	if err := c.ReadJSON(r, &user); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	user.Id = 0
+	if err := c.UserService.Create(&user); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.WriteJSON(w, http.StatusCreated, user)
+}
+
+func (c UserController) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	var user domain.User
+	if err := c.ReadJSON(r, &user); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	user.Id = id
+	if err := c.UserService.Update(&user); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	c.MarshalAndWriteHeaders(user, w)
+}
+
+func (c UserController) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := c.PathId(r)
+	if err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	if err := c.UserService.Delete(id); err != nil {
+		c.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+// Attachment is a file on an issue, Digest is the SHA-256 of its content in hex
+// and the key of the content in the BlobStore.
+type Attachment struct {
+	Id          int64     `db:"attachment_id"`
+	IssueId     int64     `db:"attachment_issueId"`
+	Name        string    `db:"attachment_name"`
+	ContentType string    `db:"attachment_contentType"`
+	Size        int64     `db:"attachment_size"`
+	Digest      string    `db:"attachment_digest"`
+	CreatedAt   time.Time `db:"attachment_createdAt"`
+}
+
+// BlobStore keeps contents by their SHA-256, the same content is only stored once.
+// Put holds the content until Unhold, so that a Delete racing with the upload of
+// the same content can't remove it before it is referenced.
+type BlobStore interface {
+	// Put returns ErrTooLarge, unchanged, when r fails with it.
+	Put(r io.Reader) (digest string, size int64, err error)
+	Unhold(digest string)
+	Open(digest string) (io.ReadSeekCloser, error)
+	// Delete leaves held contents alone.
+	Delete(digest string) error
+	// Digests lists the stored contents.
+	Digests() ([]string, error)
+}
+
+type AttachmentService interface {
+	Attachments(issueId int64) ([]*Attachment, error)
+	Upload(issueId int64, name string, r io.Reader) (*Attachment, error)
+	Open(issueId, id int64) (*Attachment, io.ReadSeekCloser, error)
+	Delete(issueId, id int64) error
+}
+
+type AttachmentRepository interface {
+	GetById(id int64) (*Attachment, error)
+	ForIssue(issueId int64) ([]*Attachment, error)
+	Create(a *Attachment) error
+	Delete(id int64) error
+	// CountDigest counts the attachments sharing the content.
+	CountDigest(digest string) (int, error)
+}
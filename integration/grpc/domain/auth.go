@@ -0,0 +1,70 @@
+package domain
+
+import "time"
+
+// Tokens are handed out by a login, AccessToken expires at ExpiresAt and
+// RefreshToken gets a new pair once.
+type Tokens struct {
+	AccessToken  string
+	ExpiresAt    time.Time
+	RefreshToken string
+}
+
+// AccessToken is what a valid signed access token says, Id tells it apart
+// from the other tokens of the user. Family is the one of the refresh tokens
+// handed out with it, empty in the tokens signed before it was added.
+type AccessToken struct {
+	Id        string
+	UserId    int64
+	Family    string
+	ExpiresAt time.Time
+}
+
+// RefreshToken is stored by the SHA-256 of its value. The tokens rotated from
+// one login share a Family and are revoked together.
+type RefreshToken struct {
+	Hash      string     `db:"refresh_hash"`
+	UserId    int64      `db:"refresh_userId"`
+	Family    string     `db:"refresh_family"`
+	ExpiresAt time.Time  `db:"refresh_expiresAt"`
+	UsedAt    *time.Time `db:"refresh_usedAt"`
+	Revoked   bool       `db:"refresh_revoked"`
+}
+
+// RevokedToken is an access token refused until it expires anyway.
+type RevokedToken struct {
+	Id        string    `db:"revoked_id"`
+	ExpiresAt time.Time `db:"revoked_expiresAt"`
+}
+
+// TokenIssuer signs access tokens and checks the tokens it signed.
+type TokenIssuer interface {
+	Issue(userId int64, family string, ttl time.Duration) (string, *AccessToken, error)
+	// Parse returns ErrUnauthorized for tokens that are invalid or expired.
+	Parse(token string) (*AccessToken, error)
+}
+
+type AuthService interface {
+	Login(email, password string) (*Tokens, error)
+	Refresh(refreshToken string) (*Tokens, error)
+	// Logout takes either token or both, and ends the whole login with either.
+	Logout(accessToken, refreshToken string) error
+	// Revoked lists the revoked access tokens that didn't expire yet.
+	Revoked() ([]*RevokedToken, error)
+	// Verify checks an access token and refuses the revoked ones.
+	Verify(accessToken string) (*AccessToken, error)
+}
+
+type TokenRepository interface {
+	CreateRefreshToken(t *RefreshToken) error
+	RefreshToken(hash string) (*RefreshToken, error)
+	// UseRefreshToken marks the token used at, false means it was used before.
+	UseRefreshToken(hash string, at time.Time) (bool, error)
+	RevokeFamily(family string) error
+	Revoke(t *RevokedToken) error
+	// IsRevoked tells whether the access token is revoked and not expired at now.
+	IsRevoked(id string, now time.Time) (bool, error)
+	Revoked(now time.Time) ([]*RevokedToken, error)
+	// PruneRevoked forgets the tokens expired at now, they are refused anyway.
+	PruneRevoked(now time.Time) error
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// Comment is a Markdown message on an issue. ParentId is the comment it replies
+// to, zero for a top level comment.
+type Comment struct {
+	Id        int64     `db:"comment_id"`
+	IssueId   int64     `db:"comment_issueId"`
+	ParentId  int64     `db:"comment_parentId"`
+	AuthorId  int64     `db:"comment_authorId"`
+	Body      string    `db:"comment_body"`
+	Deleted   bool      `db:"comment_deleted"`
+	CreatedAt time.Time `db:"comment_createdAt"`
+	UpdatedAt time.Time `db:"comment_updatedAt"`
+}
+
+// CommentRevision is a previous body of an edited comment.
+type CommentRevision struct {
+	Id        int64     `db:"revision_id"`
+	CommentId int64     `db:"revision_commentId"`
+	Body      string    `db:"revision_body"`
+	EditedAt  time.Time `db:"revision_editedAt"`
+}
+
+// CommentPage is one page of the comments of an issue, Total counts all of them.
+type CommentPage struct {
+	Comments []*Comment
+	Total    int
+	Limit    int
+	Offset   int
+}
+
+type CommentService interface {
+	Comments(issueId int64, limit, offset int) (*CommentPage, error)
+	Create(c *Comment) error
+	Edit(issueId, id int64, body string) (*Comment, error)
+	Delete(issueId, id int64) error
+	Revisions(issueId, id int64) ([]*CommentRevision, error)
+}
+
+type CommentRepository interface {
+	GetById(id int64) (*Comment, error)
+	ForIssue(issueId int64, limit, offset int) ([]*Comment, error)
+	Count(issueId int64) (int, error)
+	// Create, Update and Delete append the events to the event log in the
+	// transaction of the change.
+	Create(c *Comment, events ...*IssueEvent) error
+	// Update keeps the previous body as a CommentRevision.
+	Update(c *Comment, events ...*IssueEvent) error
+	// Delete keeps the comment in its thread but drops its body.
+	Delete(id int64, events ...*IssueEvent) error
+	Revisions(commentId int64) ([]*CommentRevision, error)
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("conflict")
+	ErrTooLarge = errors.New("too large")
+	// ErrUnauthorized is returned for bad credentials and invalid tokens.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrUnsupportedType is returned for uploads of a content type that isn't allowed.
+	ErrUnsupportedType = errors.New("unsupported content type")
+)
+
+// ValidationError is returned when an entity can't be stored as it is.
+type ValidationError struct {
+	Message string
+}
+
+func NewValidationError(msg string) *ValidationError {
+	return &ValidationError{Message: msg}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventIssueCreated      EventType = "issue.created"
+	EventIssueFieldChanged EventType = "issue.field_changed"
+	EventIssueTransitioned EventType = "issue.transitioned"
+	EventIssueDeleted      EventType = "issue.deleted"
+	EventIssueAssigned     EventType = "issue.assigned"
+	EventIssueUnassigned   EventType = "issue.unassigned"
+	EventIssueUnwatched    EventType = "issue.unwatched"
+	EventIssueCommented    EventType = "issue.commented"
+	EventCommentEdited     EventType = "issue.comment_edited"
+	EventCommentDeleted    EventType = "issue.comment_deleted"
+)
+
+// IssueEvent tells that an issue changed, ActorId is the user who changed it
+// or zero when nobody is known. Field, From and To describe field changes and
+// transitions, To of EventIssueCreated holds the new issue as JSON, written
+// before the issue got its id.
+type IssueEvent struct {
+	Id      int64     `db:"event_id"`
+	Type    EventType `db:"event_type"`
+	IssueId int64     `db:"event_issueId"`
+	ActorId int64     `db:"event_actorId"`
+	Field   string    `db:"event_field"`
+	From    string    `db:"event_from"`
+	To      string    `db:"event_to"`
+	Message string    `db:"event_message"`
+	At      time.Time `db:"event_at"`
+}
+
+type EventHandler func(e IssueEvent) error
+
+type EventPublisher interface {
+	Publish(e IssueEvent) error
+}
+
+// EventRepository is append-only, stored events are never changed.
+type EventRepository interface {
+	Append(e *IssueEvent) error
+	// ForIssue returns the events of the issue in the order they happened, the creation first.
+	ForIssue(issueId int64) ([]*IssueEvent, error)
+	// Since returns at most limit events recorded after the afterId event, the oldest first.
+	Since(afterId int64, limit int) ([]*IssueEvent, error)
+	// LastId returns the id of the latest event, 0 when there is none.
+	LastId() (int64, error)
+	// InSprint returns the ids of the issues that were ever in the sprint, the
+	// deleted ones included.
+	InSprint(sprintId int64) ([]int64, error)
+}
+
+// IssueConsistency compares a stored issue with the one rebuilt from its events.
+type IssueConsistency struct {
+	Stored      *Issue
+	Replayed    *Issue
+	Differences []string
+}
+
+type ActivityService interface {
+	Activity(issueId int64) ([]*IssueEvent, error)
+	Check(issueId int64) (*IssueConsistency, error)
+	// Since returns at most limit events of any issue recorded after the afterId event.
+	Since(afterId int64, limit int) ([]*IssueEvent, error)
+	LastId() (int64, error)
+}
+
+// IssueFields lists the fields of an issue tracked by EventIssueFieldChanged
+// and how to read them as text.
+var IssueFields = []struct {
+	Name string
+	Get  func(i *Issue) string
+}{
+	{"title", func(i *Issue) string { return i.Title }},
+	{"description", func(i *Issue) string { return i.Description }},
+	{"project", func(i *Issue) string { return strconv.FormatInt(i.ProjectId, 10) }},
+	{"owner", func(i *Issue) string { return strconv.FormatInt(i.OwnerId, 10) }},
+	{"priority", func(i *Issue) string { return i.Priority.String() }},
+	{"status", func(i *Issue) string { return string(i.Status) }},
+	{"sprint", func(i *Issue) string { return strconv.FormatInt(i.SprintId, 10) }},
+	{"points", func(i *Issue) string { return strconv.FormatFloat(i.Points, 'f', -1, 64) }},
+}
+
+// ChangedFields returns an EventIssueFieldChanged for each field that differs
+// between from and to.
+func ChangedFields(from, to *Issue) []IssueEvent {
+	var events []IssueEvent
+	for _, f := range IssueFields {
+		before, after := f.Get(from), f.Get(to)
+		if before == after {
+			continue
+		}
+		events = append(events, IssueEvent{
+			Type:    EventIssueFieldChanged,
+			IssueId: to.Id,
+			Field:   f.Name,
+			From:    before,
+			To:      after,
+			Message: fmt.Sprintf("%s of issue %d changed", f.Name, to.Id),
+		})
+	}
+	return events
+}
+
+// ReplayIssue rebuilds an issue from its events in the order they happened,
+// ErrNotFound means the issue was deleted.
+func ReplayIssue(events []*IssueEvent) (*Issue, error) {
+	var issue *Issue
+	for _, e := range events {
+		switch e.Type {
+		case EventIssueCreated:
+			issue = &Issue{}
+			if err := json.Unmarshal([]byte(e.To), issue); err != nil {
+				return nil, fmt.Errorf("event %d: %w", e.Id, err)
+			}
+			issue.Id = e.IssueId
+		case EventIssueFieldChanged, EventIssueTransitioned:
+			if issue == nil {
+				return nil, fmt.Errorf("event %d changes issue %d before it was created", e.Id, e.IssueId)
+			}
+			if err := setIssueField(issue, e.Field, e.To); err != nil {
+				return nil, fmt.Errorf("event %d: %w", e.Id, err)
+			}
+			issue.UpdatedAt = e.At
+		case EventIssueDeleted:
+			issue = nil
+		}
+	}
+	if issue == nil {
+		return nil, ErrNotFound
+	}
+	return issue, nil
+}
+
+func setIssueField(issue *Issue, field, value string) error {
+	var err error
+	switch field {
+	case "title":
+		issue.Title = value
+	case "description":
+		issue.Description = value
+	case "project":
+		issue.ProjectId, err = strconv.ParseInt(value, 10, 64)
+	case "owner":
+		issue.OwnerId, err = strconv.ParseInt(value, 10, 64)
+	case "priority":
+		issue.Priority, err = ParsePriority(value)
+	case "status":
+		issue.Status = Status(value)
+	case "sprint":
+		issue.SprintId, err = strconv.ParseInt(value, 10, 64)
+	case "points":
+		issue.Points, err = strconv.ParseFloat(value, 64)
+	default:
+		err = fmt.Errorf("unknown issue field %q", field)
+	}
+	return err
+}
+
+// Compare lists the tracked fields where the replayed issue differs from the stored one.
+func (c *IssueConsistency) Compare() {
+	c.Differences = nil
+	for _, f := range IssueFields {
+		stored, replayed := f.Get(c.Stored), f.Get(c.Replayed)
+		if stored != replayed {
+			c.Differences = append(c.Differences, fmt.Sprintf("%s is %q but the events say %q", f.Name, stored, replayed))
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestReplayIssue(t *testing.T) {
+	created := &Issue{Id: 1, Title: "Title", ProjectId: 1, Priority: PriorityLow, Status: StatusOpen}
+	snapshot, err := json.Marshal(created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := *created
+	changed.Title = "Renamed"
+	changed.Priority = PriorityHigh
+
+	changes := ChangedFields(created, &changed)
+	if len(changes) != 2 {
+		t.Fatalf("expected the title and the priority to change but got %+v", changes)
+	}
+	events := []*IssueEvent{{Type: EventIssueCreated, IssueId: 1, To: string(snapshot)}}
+	for i := range changes {
+		events = append(events, &changes[i])
+	}
+	events = append(events,
+		&IssueEvent{Type: EventIssueCommented, IssueId: 1},
+		&IssueEvent{Type: EventIssueTransitioned, IssueId: 1, Field: "status", From: "Open", To: "In Progress"},
+	)
+
+	issue, err := ReplayIssue(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed.Status = StatusInProgress
+	c := &IssueConsistency{Stored: &changed, Replayed: issue}
+	if c.Compare(); len(c.Differences) != 0 {
+		t.Fatalf("expected the replayed issue to match but got %v", c.Differences)
+	}
+
+	events = append(events, &IssueEvent{Type: EventIssueDeleted, IssueId: 1})
+	if _, err := ReplayIssue(events); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected a deleted issue to be ErrNotFound but got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type FieldType string
+
+const (
+	FieldText   FieldType = "text"
+	FieldNumber FieldType = "number"
+	FieldDate   FieldType = "date"
+	FieldEnum   FieldType = "enum"
+	FieldUser   FieldType = "user"
+)
+
+// DateLayout is the format of the date custom fields.
+const DateLayout = "2006-01-02"
+
+var FieldTypes = []FieldType{FieldText, FieldNumber, FieldDate, FieldEnum, FieldUser}
+
+func (t FieldType) IsValid() bool {
+	for _, ft := range FieldTypes {
+		if t == ft {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldOptions are the allowed values of an enum field, stored as a JSON array.
+type FieldOptions []string
+
+func (o FieldOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(o))
+	return string(b), err
+}
+
+func (o *FieldOptions) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), o)
+	case []byte:
+		return json.Unmarshal(v, o)
+	case nil:
+		*o = nil
+		return nil
+	}
+	return fmt.Errorf("can't scan %T into FieldOptions", src)
+}
+
+// FieldDefinition is a custom field the issues of a project can have.
+type FieldDefinition struct {
+	Id        int64        `db:"field_id"`
+	ProjectId int64        `db:"field_projectId"`
+	Name      string       `db:"field_name"`
+	Type      FieldType    `db:"field_type"`
+	Options   FieldOptions `db:"field_options"`
+}
+
+// Normalize checks a JSON decoded value against the field type and returns
+// the string it is stored and filtered as. Users are ids, dates use DateLayout.
+func (d *FieldDefinition) Normalize(value interface{}) (string, error) {
+	invalid := func() (string, error) {
+		return "", NewValidationError(fmt.Sprintf("invalid %s value %v for field %q", d.Type, value, d.Name))
+	}
+	switch d.Type {
+	case FieldText:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case FieldNumber, FieldUser:
+		var n float64
+		switch v := value.(type) {
+		case float64:
+			n = v
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return invalid()
+			}
+			n = f
+		default:
+			return invalid()
+		}
+		if d.Type == FieldUser && (n != float64(int64(n)) || n <= 0) {
+			return invalid()
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case FieldDate:
+		if s, ok := value.(string); ok {
+			if t, err := time.Parse(DateLayout, s); err == nil {
+				return t.Format(DateLayout), nil
+			}
+		}
+	case FieldEnum:
+		if s, ok := value.(string); ok {
+			for _, o := range d.Options {
+				if o == s {
+					return s, nil
+				}
+			}
+		}
+	}
+	return invalid()
+}
+
+// FieldValue is the value of a custom field on an issue.
+type FieldValue struct {
+	IssueId int64  `db:"value_issueId"`
+	FieldId int64  `db:"value_fieldId"`
+	Name    string `db:"field_name"`
+	Value   string `db:"value_value"`
+}
+
+type FieldService interface {
+	Fields(projectId int64) ([]*FieldDefinition, error)
+	Define(d *FieldDefinition) error
+	Delete(projectId, id int64) error
+	// Set changes the custom fields of an issue by name, a nil value removes the field.
+	Set(issueId int64, values map[string]interface{}) (map[string]string, error)
+}
+
+type FieldRepository interface {
+	GetById(id int64) (*FieldDefinition, error)
+	ForProject(projectId int64) ([]*FieldDefinition, error)
+	Create(d *FieldDefinition) error
+	// Delete also removes the values of the field.
+	Delete(id int64) error
+	Values(issueId int64) ([]*FieldValue, error)
+	// SetValues stores the set values and removes the unset fields of the issue
+	// all at once.
+	SetValues(issueId int64, set []*FieldValue, unset []int64) error
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "testing"
+
+func TestFieldDefinition_Normalize(t *testing.T) {
+	tests := []struct {
+		field FieldDefinition
+		value interface{}
+		want  string
+		ok    bool
+	}{
+		{FieldDefinition{Type: FieldText}, "**md**", "**md**", true},
+		{FieldDefinition{Type: FieldText}, 3.0, "", false},
+		{FieldDefinition{Type: FieldNumber}, 3.50, "3.5", true},
+		{FieldDefinition{Type: FieldNumber}, "2", "2", true},
+		{FieldDefinition{Type: FieldNumber}, "two", "", false},
+		{FieldDefinition{Type: FieldDate}, "2026-02-01", "2026-02-01", true},
+		{FieldDefinition{Type: FieldDate}, "01/02/2026", "", false},
+		{FieldDefinition{Type: FieldEnum, Options: FieldOptions{"low", "high"}}, "high", "high", true},
+		{FieldDefinition{Type: FieldEnum, Options: FieldOptions{"low", "high"}}, "mid", "", false},
+		{FieldDefinition{Type: FieldUser}, 4.0, "4", true},
+		{FieldDefinition{Type: FieldUser}, 4.5, "", false},
+	}
+	for _, tt := range tests {
+		got, err := tt.field.Normalize(tt.value)
+		if tt.ok && (err != nil || got != tt.want) {
+			t.Errorf("%s %v: expected %q but got %q, %v", tt.field.Type, tt.value, tt.want, got, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("%s %v: expected an error but got %q", tt.field.Type, tt.value, got)
+		}
+	}
+}
@@ -1,24 +1,65 @@
 package domain
 
+import "time"
+
 type Issue struct {
-	Id          int64    `db:"issue_id"`
-	Title       string   `db:"issue_title"`
-	Description string   `db:"issue_description"`
-	ProjectId   int64    `db:"issue_projectId"`
-	OwnerId     int64    `db:"issue_ownerId"`
-	Priority    Priority `db:"issue_priority"`
+	Id          int64     `db:"issue_id"`
+	Title       string    `db:"issue_title"`
+	Description string    `db:"issue_description"`
+	ProjectId   int64     `db:"issue_projectId"`
+	OwnerId     int64     `db:"issue_ownerId"`
+	Priority    Priority  `db:"issue_priority"`
+	Status      Status    `db:"issue_status"`
+	SprintId    int64     `db:"issue_sprintId"`
+	Points      float64   `db:"issue_points"`
+	CreatedAt   time.Time `db:"issue_createdAt"`
+	UpdatedAt   time.Time `db:"issue_updatedAt"`
+
+	Labels    []*Label          `db:"-"`
+	Fields    map[string]string `db:"-"`
+	Assignees []int64           `db:"-"`
+	Watchers  []int64           `db:"-"`
+	// DueBy is when the issue breaches the SLA of its priority.
+	DueBy *time.Time `db:"-"`
+}
+
+// IssueFilter narrows issue listings, zero values match every issue.
+// An issue has to carry all the Labels and match all the Fields by name.
+type IssueFilter struct {
+	ProjectId int64
+	SprintId  int64
+	Labels    []string
+	Fields    map[string]string
 }
 
 type IssueService interface {
 	Issue(id int64) (*Issue, error)
-	Issues() ([]*Issue, error)
+	Issues(filter IssueFilter) ([]*Issue, error)
+	// Search runs a query written in the language of ParseQuery for the userId user.
+	Search(query string, userId int64) ([]*Issue, error)
 	Create(issue *Issue) error
+	Update(issue *Issue) error
 	Delete(id int64) error
+	Transition(id int64, to Status, userId int64) (*Issue, error)
+	History(id int64) ([]*HistoryEntry, error)
+	// Breaching lists the issues past their SLA, the longest overdue first.
+	Breaching() ([]*Issue, error)
 }
 
 type IssueRepository interface {
 	GetById(id int64) (*Issue, error)
 	All() ([]*Issue, error)
-	Create(issue *Issue) error
-	Delete(id int64) error
+	Find(filter IssueFilter) ([]*Issue, error)
+	// Query resolves "me" in the query to the me user.
+	Query(q *Query, me int64) ([]*Issue, error)
+	// CreatedBefore returns the issues of each priority created before its cutoff
+	// and not in one of the excluded states.
+	CreatedBefore(cutoffs map[Priority]time.Time, excluded []Status) ([]*Issue, error)
+	// Create, Update and Delete append the events to the activity log in the
+	// transaction of the change, Update also adds the history entries.
+	// Create gives the events the id of the new issue, Delete also removes what
+	// belongs to the issue but its events.
+	Create(issue *Issue, events ...*IssueEvent) error
+	Update(issue *Issue, history []*HistoryEntry, events ...*IssueEvent) error
+	Delete(id int64, events ...*IssueEvent) error
 }
@@ -0,0 +1,28 @@
+package domain
+
+// Label tags issues of a single project, Color is a "#rrggbb" string.
+type Label struct {
+	Id        int64  `db:"label_id"`
+	ProjectId int64  `db:"label_projectId"`
+	Name      string `db:"label_name"`
+	Color     string `db:"label_color"`
+}
+
+type LabelService interface {
+	Labels(projectId int64) ([]*Label, error)
+	Create(l *Label) error
+	Delete(projectId, id int64) error
+	Attach(issueId, labelId int64) error
+	Detach(issueId, labelId int64) error
+}
+
+type LabelRepository interface {
+	GetById(id int64) (*Label, error)
+	ForProject(projectId int64) ([]*Label, error)
+	ForIssue(issueId int64) ([]*Label, error)
+	Create(l *Label) error
+	// Delete also detaches the label from its issues.
+	Delete(id int64) error
+	Attach(issueId, labelId int64) error
+	Detach(issueId, labelId int64) error
+}
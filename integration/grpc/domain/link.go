@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type LinkType string
+
+// The link types are stored in one direction, their inverses are swapped on the way in.
+const (
+	LinkBlocks     LinkType = "blocks"
+	LinkDuplicates LinkType = "duplicates"
+	LinkRelatesTo  LinkType = "relates-to"
+	LinkParentOf   LinkType = "parent-of"
+)
+
+var LinkTypes = []LinkType{LinkBlocks, LinkDuplicates, LinkRelatesTo, LinkParentOf}
+
+// linkInverses maps the names of the inverse links to the stored types.
+var linkInverses = map[string]LinkType{
+	"blocked-by":    LinkBlocks,
+	"duplicated-by": LinkDuplicates,
+	"child-of":      LinkParentOf,
+}
+
+// IssueLink reads "FromId Type ToId", like "1 blocks 2".
+type IssueLink struct {
+	Id     int64    `db:"link_id"`
+	FromId int64    `db:"link_fromId"`
+	ToId   int64    `db:"link_toId"`
+	Type   LinkType `db:"link_type"`
+}
+
+// NewIssueLink links from to to with any link type or inverse name and turns
+// it into its stored form. relates-to goes both ways, it always starts at the smaller id.
+func NewIssueLink(from, to int64, name string) (*IssueLink, error) {
+	if from == to {
+		return nil, NewValidationError("an issue can't be linked to itself")
+	}
+	if t, ok := linkInverses[name]; ok {
+		return &IssueLink{FromId: to, ToId: from, Type: t}, nil
+	}
+	t := LinkType(name)
+	if !t.IsValid() {
+		return nil, NewValidationError(fmt.Sprintf("link type must be one of %v or their inverses", LinkTypes))
+	}
+	if t == LinkRelatesTo && from > to {
+		from, to = to, from
+	}
+	return &IssueLink{FromId: from, ToId: to, Type: t}, nil
+}
+
+func (t LinkType) IsValid() bool {
+	for _, known := range LinkTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Acyclic link types can't loop back to where they started.
+func (t LinkType) Acyclic() bool {
+	return t == LinkBlocks || t == LinkParentOf
+}
+
+type LinkService interface {
+	Links(issueId int64) ([]*IssueLink, error)
+	Link(issueId, otherId int64, linkType string) (*IssueLink, error)
+	Unlink(issueId, linkId int64) error
+	// Blockers returns every issue blocking the issue, directly or through other issues.
+	Blockers(issueId int64) ([]*Issue, error)
+	DependencyGraph(projectId int64) (*DependencyGraph, error)
+}
+
+type LinkRepository interface {
+	GetById(id int64) (*IssueLink, error)
+	ForIssue(issueId int64) ([]*IssueLink, error)
+	ForProject(projectId int64) ([]*IssueLink, error)
+	// Create refuses acyclic links that would loop back to their issue with a ValidationError.
+	Create(l *IssueLink) error
+	Delete(id int64) error
+	// Upstream returns the ids of the issues reaching issueId through links of the type.
+	Upstream(issueId int64, t LinkType) ([]int64, error)
+}
+
+// GraphNode is an issue of a DependencyGraph, External ones belong to another project.
+type GraphNode struct {
+	Id       int64
+	Title    string
+	Status   Status
+	External bool
+}
+
+type DependencyGraph struct {
+	ProjectId int64
+	Nodes     []*GraphNode
+	Edges     []*IssueLink
+}
+
+// DOT renders the graph for Graphviz, blocking links are drawn in red.
+func (g *DependencyGraph) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph project_%d {\n", g.ProjectId)
+	nodes := append([]*GraphNode(nil), g.Nodes...)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Id < nodes[j].Id })
+	for _, n := range nodes {
+		style := ""
+		if n.External {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "\t%d [label=%q%s];\n", n.Id, fmt.Sprintf("#%d %s (%s)", n.Id, n.Title, n.Status), style)
+	}
+	for _, e := range g.Edges {
+		attrs := fmt.Sprintf("label=%q", e.Type)
+		switch e.Type {
+		case LinkBlocks:
+			attrs += ", color=red"
+		case LinkRelatesTo:
+			attrs += ", dir=none, style=dotted"
+		}
+		fmt.Fprintf(&b, "\t%d -> %d [%s];\n", e.FromId, e.ToId, attrs)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewIssueLink(t *testing.T) {
+	tests := []struct {
+		from, to int64
+		name     string
+		want     IssueLink
+		ok       bool
+	}{
+		{1, 2, "blocks", IssueLink{FromId: 1, ToId: 2, Type: LinkBlocks}, true},
+		{1, 2, "blocked-by", IssueLink{FromId: 2, ToId: 1, Type: LinkBlocks}, true},
+		{3, 2, "child-of", IssueLink{FromId: 2, ToId: 3, Type: LinkParentOf}, true},
+		{3, 2, "relates-to", IssueLink{FromId: 2, ToId: 3, Type: LinkRelatesTo}, true},
+		{1, 1, "blocks", IssueLink{}, false},
+		{1, 2, "causes", IssueLink{}, false},
+	}
+	for _, tt := range tests {
+		link, err := NewIssueLink(tt.from, tt.to, tt.name)
+		if !tt.ok {
+			if err == nil {
+				t.Errorf("%d %s %d: expected an error", tt.from, tt.name, tt.to)
+			}
+			continue
+		}
+		if err != nil || *link != tt.want {
+			t.Errorf("%d %s %d: expected %+v but got %+v, %v", tt.from, tt.name, tt.to, tt.want, link, err)
+		}
+	}
+}
+
+func TestDependencyGraph_DOT(t *testing.T) {
+	g := &DependencyGraph{
+		ProjectId: 1,
+		Nodes:     []*GraphNode{{Id: 2, Title: `say "hi"`, Status: StatusOpen}, {Id: 1, Title: "a", Status: StatusClosed, External: true}},
+		Edges:     []*IssueLink{{FromId: 1, ToId: 2, Type: LinkBlocks}},
+	}
+	dot := g.DOT()
+	for _, want := range []string{"digraph project_1 {", `2 [label="#2 say \"hi\" (Open)"];`, "style=dashed", `1 -> 2 [label="blocks", color=red];`} {
+		if !strings.Contains(dot, want) {
+			t.Fatalf("expected %q in\n%s", want, dot)
+		}
+	}
+}
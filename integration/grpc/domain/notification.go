@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// Notification is an entry of the inbox of a user about an issue they take part in.
+type Notification struct {
+	Id        int64     `db:"notification_id"`
+	UserId    int64     `db:"notification_userId"`
+	IssueId   int64     `db:"notification_issueId"`
+	Event     EventType `db:"notification_event"`
+	Message   string    `db:"notification_message"`
+	Read      bool      `db:"notification_read"`
+	CreatedAt time.Time `db:"notification_createdAt"`
+}
+
+type NotificationService interface {
+	Notifications(userId int64, unreadOnly bool) ([]*Notification, error)
+	MarkRead(userId, id int64) error
+	MarkAllRead(userId int64) error
+}
+
+type NotificationRepository interface {
+	GetById(id int64) (*Notification, error)
+	ForUser(userId int64, unreadOnly bool) ([]*Notification, error)
+	Create(n *Notification) error
+	MarkRead(id int64) error
+	MarkAllRead(userId int64) error
+}
+
+// ParticipantService manages the assignees and the watchers of issues.
+type ParticipantService interface {
+	Assign(issueId, userId int64) error
+	Unassign(issueId, userId int64) error
+	Watch(issueId, userId int64) error
+	Unwatch(issueId, userId int64) error
+}
+
+type ParticipantRepository interface {
+	Assignees(issueId int64) ([]int64, error)
+	Watchers(issueId int64) ([]int64, error)
+	Assign(issueId, userId int64) error
+	Unassign(issueId, userId int64) error
+	Watch(issueId, userId int64) error
+	Unwatch(issueId, userId int64) error
+}
@@ -1,9 +1,176 @@
 package domain
 
-type Priority string
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Priority is ordered from PriorityLow to PriorityHigh, the zero value is no priority.
+// It is stored and sent as its name.
+type Priority int
 
-var (
-	PriorityLow    Priority = "Low"
-	PriorityMedium Priority = "Medium"
-	PriorityHigh   Priority = "High"
+const (
+	PriorityLow Priority = iota + 1
+	PriorityMedium
+	PriorityHigh
 )
+
+var Priorities = []Priority{PriorityLow, PriorityMedium, PriorityHigh}
+
+var priorityNames = map[Priority]string{
+	PriorityLow:    "Low",
+	PriorityMedium: "Medium",
+	PriorityHigh:   "High",
+}
+
+func ParsePriority(name string) (Priority, error) {
+	for p, known := range priorityNames {
+		if strings.EqualFold(name, known) {
+			return p, nil
+		}
+	}
+	return 0, NewValidationError(fmt.Sprintf("priority must be one of %v", Priorities))
+}
+
+func (p Priority) IsValid() bool {
+	_, ok := priorityNames[p]
+	return ok
+}
+
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Priority(%d)", int(p))
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) {
+	if !p.IsValid() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(p.String())
+}
+
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	var name *string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	if name == nil {
+		*p = 0
+		return nil
+	}
+	parsed, err := ParsePriority(*name)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// Value refuses to store anything but a known priority.
+func (p Priority) Value() (driver.Value, error) {
+	if !p.IsValid() {
+		return nil, NewValidationError(fmt.Sprintf("priority must be one of %v", Priorities))
+	}
+	return p.String(), nil
+}
+
+// Scan reads NULL as no priority.
+func (p *Priority) Scan(src interface{}) error {
+	var name string
+	switch src := src.(type) {
+	case nil:
+		*p = 0
+		return nil
+	case string:
+		name = src
+	case []byte:
+		name = string(src)
+	default:
+		return fmt.Errorf("can't scan %T into a priority", src)
+	}
+	parsed, err := ParsePriority(name)
+	if err != nil {
+		return fmt.Errorf("unknown priority %q in the database", name)
+	}
+	*p = parsed
+	return nil
+}
+
+// SLA is the time an issue of each priority may stay unresolved after it was created.
+type SLA map[Priority]time.Duration
+
+// DoneStatuses are the states where an issue no longer runs against its SLA.
+var DoneStatuses = []Status{StatusResolved, StatusClosed}
+
+func DefaultSLA() SLA {
+	return SLA{
+		PriorityHigh:   24 * time.Hour,
+		PriorityMedium: 3 * 24 * time.Hour,
+		PriorityLow:    14 * 24 * time.Hour,
+	}
+}
+
+// ParseSLA reads an SLA like "High=24h,Medium=72h,Low=336h", the priorities
+// left out have no SLA.
+func ParseSLA(s string) (SLA, error) {
+	sla := SLA{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid SLA %q, expected priority=duration", part)
+		}
+		p, err := ParsePriority(strings.TrimSpace(kv[0]))
+		if err != nil {
+			return nil, err
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(kv[1]))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid SLA duration %q for %s", kv[1], p)
+		}
+		sla[p] = d
+	}
+	return sla, nil
+}
+
+func (s SLA) String() string {
+	parts := make([]string, 0, len(s))
+	for p, d := range s {
+		parts = append(parts, fmt.Sprintf("%s=%s", p, d))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// DueBy returns when the issue breaches its SLA, nil when its priority has no SLA
+// or the issue is done.
+func (s SLA) DueBy(issue *Issue) *time.Time {
+	d, ok := s[issue.Priority]
+	if !ok {
+		return nil
+	}
+	if isDone(issue.Status) {
+		return nil
+	}
+	due := issue.CreatedAt.Add(d)
+	return &due
+}
+
+// Cutoffs returns, for each priority with an SLA, the creation time before which
+// an open issue breaches it at now.
+func (s SLA) Cutoffs(now time.Time) map[Priority]time.Time {
+	cutoffs := make(map[Priority]time.Time, len(s))
+	for p, d := range s {
+		cutoffs[p] = now.Add(-d)
+	}
+	return cutoffs
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPriority_JSON(t *testing.T) {
+	var issue Issue
+	if err := json.Unmarshal([]byte(`{"Priority":"High"}`), &issue); err != nil {
+		t.Fatal(err)
+	}
+	if issue.Priority != PriorityHigh || !(PriorityHigh > PriorityMedium) {
+		t.Fatalf("expected High above Medium but got %v", issue.Priority)
+	}
+	data, err := json.Marshal(PriorityMedium)
+	if err != nil || string(data) != `"Medium"` {
+		t.Fatalf(`expected "Medium" but got %s, %v`, data, err)
+	}
+	if err := json.Unmarshal([]byte(`{"Priority":"Urgent"}`), &issue); err == nil {
+		t.Fatal("expected an unknown priority to be rejected")
+	}
+}
+
+func TestPriority_SQL(t *testing.T) {
+	v, err := PriorityLow.Value()
+	if err != nil || v != "Low" {
+		t.Fatalf(`expected "Low" but got %v, %v`, v, err)
+	}
+	if _, err := Priority(0).Value(); err == nil {
+		t.Fatal("expected an unset priority to be refused")
+	}
+	var p Priority
+	if err := p.Scan([]byte("Medium")); err != nil || p != PriorityMedium {
+		t.Fatalf("expected Medium but got %v, %v", p, err)
+	}
+	if err := p.Scan("Urgent"); err == nil {
+		t.Fatal("expected an unknown stored priority to be rejected")
+	}
+}
+
+func TestSLA_DueBy(t *testing.T) {
+	sla, err := ParseSLA("High=24h, Medium=72h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	due := sla.DueBy(&Issue{Priority: PriorityHigh, Status: StatusOpen, CreatedAt: created})
+	if due == nil || !due.Equal(created.Add(24*time.Hour)) {
+		t.Fatalf("expected a day after creation but got %v", due)
+	}
+	if due := sla.DueBy(&Issue{Priority: PriorityLow, CreatedAt: created}); due != nil {
+		t.Fatalf("expected no SLA for Low but got %v", due)
+	}
+	if due := sla.DueBy(&Issue{Priority: PriorityHigh, Status: StatusClosed, CreatedAt: created}); due != nil {
+		t.Fatalf("expected no due date for a closed issue but got %v", due)
+	}
+	if _, err := ParseSLA("High=soon"); err == nil {
+		t.Fatal("expected an invalid duration to be rejected")
+	}
+}
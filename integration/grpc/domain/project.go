@@ -11,12 +11,17 @@ type ProjectService interface {
 	Project(id int64) (*Project, error)
 	Projects() ([]*Project, error)
 	Create(issue *Project) error
+	Update(p *Project) error
 	Delete(id int64) error
+	Workflow(projectId int64) (*Workflow, error)
+	SetWorkflow(w *Workflow) error
 }
 
 type ProjectRepository interface {
 	GetById(id int64) (*Project, error)
 	All() ([]*Project, error)
 	Create(issue *Project) error
+	Update(p *Project) error
+	// Delete refuses with ErrConflict while the project has issues.
 	Delete(id int64) error
 }
@@ -0,0 +1,467 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// QueryKind tells how the values compared to a field are checked.
+type QueryKind string
+
+const (
+	QueryNumber   QueryKind = "number"
+	QueryText     QueryKind = "text"
+	QueryUser     QueryKind = "user"
+	QueryDate     QueryKind = "date"
+	QueryPriority QueryKind = "priority"
+)
+
+// QueryDateLayout is the layout of the dates in a query.
+const QueryDateLayout = "2006-01-02"
+
+const (
+	OpEqual        = "="
+	OpNotEqual     = "!="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+	OpContains     = "~"
+	OpIn           = "in"
+	OpNotIn        = "not in"
+)
+
+var (
+	equalOps   = []string{OpEqual, OpNotEqual, OpIn, OpNotIn}
+	textOps    = []string{OpEqual, OpNotEqual, OpContains, OpIn, OpNotIn}
+	compareOps = []string{OpEqual, OpNotEqual, OpLess, OpLessEqual, OpGreater, OpGreaterEqual, OpIn, OpNotIn}
+	dateOps    = []string{OpEqual, OpNotEqual, OpLess, OpLessEqual, OpGreater, OpGreaterEqual}
+)
+
+// QueryField is a field the issue query language knows.
+type QueryField struct {
+	Kind      QueryKind
+	Ops       []string
+	Orderable bool
+}
+
+// QueryFields are the fields an issue query can use, "me" stands for the
+// user running the query in the user fields.
+var QueryFields = map[string]QueryField{
+	"id":          {Kind: QueryNumber, Ops: compareOps, Orderable: true},
+	"project":     {Kind: QueryNumber, Ops: equalOps, Orderable: true},
+	"priority":    {Kind: QueryPriority, Ops: compareOps, Orderable: true},
+	"status":      {Kind: QueryText, Ops: equalOps, Orderable: true},
+	"title":       {Kind: QueryText, Ops: textOps, Orderable: true},
+	"description": {Kind: QueryText, Ops: textOps},
+	"sprint":      {Kind: QueryNumber, Ops: equalOps, Orderable: true},
+	"owner":       {Kind: QueryUser, Ops: equalOps},
+	"assignee":    {Kind: QueryUser, Ops: equalOps},
+	"watcher":     {Kind: QueryUser, Ops: equalOps},
+	"label":       {Kind: QueryText, Ops: equalOps},
+	"created":     {Kind: QueryDate, Ops: dateOps, Orderable: true},
+	"updated":     {Kind: QueryDate, Ops: dateOps, Orderable: true},
+}
+
+// QueryExpr is a node of the WHERE part of a query:
+// QueryAnd, QueryOr, QueryNot or QueryCondition.
+type QueryExpr interface {
+	queryExpr()
+}
+
+type QueryAnd struct {
+	Left, Right QueryExpr
+}
+
+type QueryOr struct {
+	Left, Right QueryExpr
+}
+
+type QueryNot struct {
+	Expr QueryExpr
+}
+
+// QueryCondition compares a field to its values, only the in operators take
+// more than one value.
+type QueryCondition struct {
+	Field  string
+	Op     string
+	Values []QueryValue
+}
+
+// QueryValue is a value as written in the query, Me is set for "me" in a user field.
+type QueryValue struct {
+	Text string
+	Me   bool
+}
+
+type QueryOrder struct {
+	Field string
+	Desc  bool
+}
+
+// Query is a parsed issue query, a nil Where matches every issue.
+type Query struct {
+	Where   QueryExpr
+	OrderBy []QueryOrder
+}
+
+func (QueryAnd) queryExpr()       {}
+func (QueryOr) queryExpr()        {}
+func (QueryNot) queryExpr()       {}
+func (QueryCondition) queryExpr() {}
+
+// ParseQuery parses and validates a query like
+//
+//	project = 3 AND priority >= Medium AND assignee = me ORDER BY updated DESC
+//
+// Keywords are case insensitive, values with spaces go between quotes.
+func ParseQuery(s string) (*Query, error) {
+	tokens, err := lexQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	return p.query()
+}
+
+type queryTokenKind int
+
+const (
+	tokenEOF queryTokenKind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+func queryError(pos int, format string, args ...interface{}) error {
+	return NewValidationError(fmt.Sprintf("query: "+format+" at position %d", append(args, pos+1)...))
+}
+
+func isQueryWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.:", r)
+}
+
+func lexQuery(s string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{tokenLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{tokenRParen, ")", i})
+			i++
+		case r == ',':
+			tokens = append(tokens, queryToken{tokenComma, ",", i})
+			i++
+		case r == '=' || r == '~':
+			tokens = append(tokens, queryToken{tokenOp, string(r), i})
+			i++
+		case r == '!' || r == '<' || r == '>':
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, queryToken{tokenOp, string(runes[i : i+2]), i})
+				i += 2
+				continue
+			}
+			if r == '!' {
+				return nil, queryError(i, "expected !=")
+			}
+			tokens = append(tokens, queryToken{tokenOp, string(r), i})
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, queryError(i, "unterminated string")
+			}
+			tokens = append(tokens, queryToken{tokenString, string(runes[i+1 : end]), i})
+			i = end + 1
+		case isQueryWordRune(r):
+			end := i
+			for end < len(runes) && isQueryWordRune(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, queryToken{tokenWord, string(runes[i:end]), i})
+			i = end
+		default:
+			return nil, queryError(i, "unexpected %q", r)
+		}
+	}
+	return append(tokens, queryToken{tokenEOF, "", len(runes)}), nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *queryParser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// keyword consumes the next token when it is the keyword.
+func (p *queryParser) keyword(keyword string) bool {
+	if p.isKeyword(keyword) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) expect(kind queryTokenKind, what string) (queryToken, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, queryError(t.pos, "expected %s", what)
+	}
+	return t, nil
+}
+
+func (p *queryParser) query() (*Query, error) {
+	q := &Query{}
+	if p.peek().kind != tokenEOF && !p.isKeyword("order") {
+		where, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		q.Where = where
+	}
+	if p.keyword("order") {
+		if !p.keyword("by") {
+			return nil, queryError(p.peek().pos, "expected BY")
+		}
+		for {
+			order, err := p.order()
+			if err != nil {
+				return nil, err
+			}
+			q.OrderBy = append(q.OrderBy, order)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, queryError(t.pos, "unexpected %q", t.text)
+	}
+	return q, nil
+}
+
+func (p *queryParser) order() (QueryOrder, error) {
+	t, err := p.expect(tokenWord, "a field")
+	if err != nil {
+		return QueryOrder{}, err
+	}
+	name := strings.ToLower(t.text)
+	if field, ok := QueryFields[name]; !ok || !field.Orderable {
+		return QueryOrder{}, queryError(t.pos, "can't order by %q", t.text)
+	}
+	order := QueryOrder{Field: name}
+	if p.keyword("desc") {
+		order.Desc = true
+	} else {
+		p.keyword("asc")
+	}
+	return order, nil
+}
+
+func (p *queryParser) or() (QueryExpr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = QueryOr{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) and() (QueryExpr, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = QueryAnd{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) not() (QueryExpr, error) {
+	if p.keyword("not") {
+		expr, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return QueryNot{Expr: expr}, nil
+	}
+	if p.peek().kind == tokenLParen {
+		p.next()
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+	return p.condition()
+}
+
+func (p *queryParser) condition() (QueryExpr, error) {
+	t, err := p.expect(tokenWord, "a field")
+	if err != nil {
+		return nil, err
+	}
+	name := strings.ToLower(t.text)
+	field, ok := QueryFields[name]
+	if !ok {
+		return nil, queryError(t.pos, "unknown field %q", t.text)
+	}
+
+	opToken := p.peek()
+	var op string
+	switch {
+	case opToken.kind == tokenOp:
+		op = p.next().text
+	case p.keyword("in"):
+		op = OpIn
+	case p.keyword("not"):
+		if !p.keyword("in") {
+			return nil, queryError(p.peek().pos, "expected IN")
+		}
+		op = OpNotIn
+	default:
+		return nil, queryError(opToken.pos, "expected an operator after %q", t.text)
+	}
+	if !hasOp(field.Ops, op) {
+		return nil, queryError(opToken.pos, "%q can't be used with %s", op, name)
+	}
+
+	cond := QueryCondition{Field: name, Op: op}
+	if op != OpIn && op != OpNotIn {
+		v, err := p.value(field)
+		if err != nil {
+			return nil, err
+		}
+		cond.Values = []QueryValue{v}
+		return cond, nil
+	}
+	if _, err := p.expect(tokenLParen, "("); err != nil {
+		return nil, err
+	}
+	for {
+		v, err := p.value(field)
+		if err != nil {
+			return nil, err
+		}
+		cond.Values = append(cond.Values, v)
+		if p.peek().kind != tokenComma {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expect(tokenRParen, ")"); err != nil {
+		return nil, err
+	}
+	return cond, nil
+}
+
+func (p *queryParser) value(field QueryField) (QueryValue, error) {
+	t := p.next()
+	if t.kind != tokenWord && t.kind != tokenString {
+		return QueryValue{}, queryError(t.pos, "expected a value")
+	}
+	switch field.Kind {
+	case QueryUser:
+		if t.kind == tokenWord && strings.EqualFold(t.text, "me") {
+			return QueryValue{Text: t.text, Me: true}, nil
+		}
+		fallthrough
+	case QueryNumber:
+		if _, err := strconv.ParseInt(t.text, 10, 64); err != nil {
+			return QueryValue{}, queryError(t.pos, "%q is not a number", t.text)
+		}
+	case QueryDate:
+		if _, err := time.Parse(QueryDateLayout, t.text); err != nil {
+			return QueryValue{}, queryError(t.pos, "%q is not a date like %s", t.text, QueryDateLayout)
+		}
+	case QueryPriority:
+		if _, err := ParsePriority(t.text); err != nil {
+			return QueryValue{}, queryError(t.pos, "priority must be one of %v", Priorities)
+		}
+	}
+	return QueryValue{Text: t.text}, nil
+}
+
+func hasOp(ops []string, op string) bool {
+	for _, known := range ops {
+		if op == known {
+			return true
+		}
+	}
+	return false
+}
+
+// SavedQuery is a named issue query of a user.
+type SavedQuery struct {
+	Id     int64  `db:"query_id"`
+	UserId int64  `db:"query_userId"`
+	Name   string `db:"query_name"`
+	Query  string `db:"query_text"`
+}
+
+type SavedQueryService interface {
+	Queries(userId int64) ([]*SavedQuery, error)
+	Save(q *SavedQuery) error
+	Delete(userId, id int64) error
+	Run(userId, id int64) ([]*Issue, error)
+}
+
+type SavedQueryRepository interface {
+	GetById(id int64) (*SavedQuery, error)
+	ForUser(userId int64) ([]*SavedQuery, error)
+	Create(q *SavedQuery) error
+	Delete(id int64) error
+}
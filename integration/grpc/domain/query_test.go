@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		ok    bool
+	}{
+		{"", true},
+		{"project = 3 AND priority in (High, Medium) AND assignee = me ORDER BY updated DESC", true},
+		{`status = "In Progress" OR NOT (label = bug)`, true},
+		{"title ~ crash and created >= 2026-01-01 order by priority, id asc", true},
+		{"label not in (bug, ui)", true},
+		{"color = red", false},
+		{"priority = Urgent", false},
+		{"project ~ 3", false},
+		{"owner = someone", false},
+		{"created > yesterday", false},
+		{"project = 3 AND", false},
+		{"(project = 3", false},
+		{`title = "open`, false},
+		{"project = 3 ORDER BY description", false},
+		{"project = 3 project = 4", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			if tt.ok && err != nil {
+				t.Fatalf("expected the query to parse but got %v", err)
+			}
+			var verr *ValidationError
+			if !tt.ok && !errors.As(err, &verr) {
+				t.Fatalf("expected a validation error but got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseQuery_Precedence(t *testing.T) {
+	q, err := ParseQuery("project = 1 OR project = 2 AND assignee = me")
+	if err != nil {
+		t.Fatal(err)
+	}
+	or, ok := q.Where.(QueryOr)
+	if !ok {
+		t.Fatalf("expected OR at the root but got %#v", q.Where)
+	}
+	and, ok := or.Right.(QueryAnd)
+	if !ok {
+		t.Fatalf("expected AND to bind tighter than OR but got %#v", or.Right)
+	}
+	if cond := and.Right.(QueryCondition); !cond.Values[0].Me {
+		t.Fatalf("expected me to be recognised but got %#v", cond)
+	}
+}
@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"time"
+)
+
+type SprintKind string
+
+const (
+	SprintKindSprint    SprintKind = "sprint"
+	SprintKindMilestone SprintKind = "milestone"
+)
+
+// Sprint is a time box of a project, Start and End are whole UTC days and End
+// is the last day of the sprint.
+type Sprint struct {
+	Id        int64      `db:"sprint_id"`
+	ProjectId int64      `db:"sprint_projectId"`
+	Name      string     `db:"sprint_name"`
+	Kind      SprintKind `db:"sprint_kind"`
+	Start     time.Time  `db:"sprint_start"`
+	End       time.Time  `db:"sprint_end"`
+}
+
+// BurndownDay is the state of a sprint at the end of Date, in story points.
+type BurndownDay struct {
+	Date      string
+	Scope     float64
+	Done      float64
+	Remaining float64
+	Ideal     float64
+}
+
+// SprintReport holds one BurndownDay per day of the sprint up to today,
+// it reads as a burndown through Remaining and as a burnup through Done and Scope.
+type SprintReport struct {
+	Sprint *Sprint
+	Days   []BurndownDay
+}
+
+// IssueTimeline is what is known about the past of an issue: its events and,
+// for issues older than the event log, its current state.
+type IssueTimeline struct {
+	Current *Issue
+	Events  []*IssueEvent
+}
+
+type SprintService interface {
+	Sprints(projectId int64) ([]*Sprint, error)
+	Create(s *Sprint) error
+	Delete(projectId, id int64) error
+	Report(projectId, id int64) (*SprintReport, error)
+}
+
+type SprintRepository interface {
+	GetById(id int64) (*Sprint, error)
+	ForProject(projectId int64) ([]*Sprint, error)
+	Create(s *Sprint) error
+	// Delete returns ErrConflict while issues are still in the sprint.
+	Delete(id int64) error
+}
+
+func Day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// At returns the issue as it was at t, nil when it did not exist.
+func (tl IssueTimeline) At(t time.Time) *Issue {
+	var events []*IssueEvent
+	created := false
+	for _, e := range tl.Events {
+		if e.Type == EventIssueCreated {
+			created = true
+		}
+		if !e.At.After(t) {
+			events = append(events, e)
+		}
+	}
+	if !created {
+		if tl.Current == nil || tl.Current.CreatedAt.After(t) {
+			return nil
+		}
+		return tl.Current
+	}
+	issue, err := ReplayIssue(events)
+	if err != nil {
+		return nil
+	}
+	return issue
+}
+
+// Burndown computes the report of the sprint from the timelines of every issue
+// that was in it at some point, the days after now are left out.
+func Burndown(s *Sprint, timelines []IssueTimeline, now time.Time) *SprintReport {
+	report := &SprintReport{Sprint: s, Days: []BurndownDay{}}
+	start, end := Day(s.Start), Day(s.End)
+	days := int(end.Sub(start).Hours()/24) + 1
+	var initialScope float64
+	for d := 0; d < days; d++ {
+		day := start.AddDate(0, 0, d)
+		if day.After(now) {
+			break
+		}
+		endOfDay := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		if endOfDay.After(now) {
+			endOfDay = now
+		}
+		entry := BurndownDay{Date: day.Format("2006-01-02")}
+		for _, tl := range timelines {
+			issue := tl.At(endOfDay)
+			if issue == nil || issue.SprintId != s.Id {
+				continue
+			}
+			entry.Scope += issue.Points
+			if isDone(issue.Status) {
+				entry.Done += issue.Points
+			}
+		}
+		entry.Remaining = entry.Scope - entry.Done
+		if d == 0 {
+			initialScope = entry.Scope
+		}
+		entry.Ideal = initialScope
+		if days > 1 {
+			entry.Ideal = initialScope * float64(days-1-d) / float64(days-1)
+		}
+		report.Days = append(report.Days, entry)
+	}
+	return report
+}
+
+func isDone(s Status) bool {
+	for _, done := range DoneStatuses {
+		if s == done {
+			return true
+		}
+	}
+	return false
+}
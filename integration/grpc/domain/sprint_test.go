@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestBurndown(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 0, 0, 0, time.UTC) }
+	sprint := &Sprint{Id: 7, Start: day(2, 0), End: day(5, 0)}
+	created := func(i Issue, at time.Time) *IssueEvent {
+		snapshot, _ := json.Marshal(i)
+		return &IssueEvent{Type: EventIssueCreated, IssueId: i.Id, To: string(snapshot), At: at}
+	}
+
+	timelines := []IssueTimeline{
+		// 3 points in the sprint from the start, done on the 3rd
+		{Events: []*IssueEvent{
+			created(Issue{Id: 1, Priority: PriorityLow, Status: StatusOpen, SprintId: 7, Points: 3}, day(1, 9)),
+			{Type: EventIssueTransitioned, Field: "status", To: string(StatusClosed), At: day(3, 12)},
+		}},
+		// 5 points added on the 4th
+		{Events: []*IssueEvent{
+			created(Issue{Id: 2, Priority: PriorityLow, Status: StatusOpen, Points: 5}, day(1, 9)),
+			{Type: EventIssueFieldChanged, Field: "sprint", From: "0", To: "7", At: day(4, 8)},
+		}},
+		// older than the event log, in the sprint all along
+		{Current: &Issue{Id: 3, Status: StatusOpen, SprintId: 7, Points: 2, CreatedAt: day(1, 0)}},
+	}
+
+	report := Burndown(sprint, timelines, day(4, 18))
+	want := []BurndownDay{
+		{Date: "2026-03-02", Scope: 5, Done: 0, Remaining: 5, Ideal: 5},
+		{Date: "2026-03-03", Scope: 5, Done: 3, Remaining: 2, Ideal: 5 * 2.0 / 3},
+		{Date: "2026-03-04", Scope: 10, Done: 3, Remaining: 7, Ideal: 5 * 1.0 / 3},
+	}
+	if len(report.Days) != len(want) {
+		t.Fatalf("expected %d days up to now but got %+v", len(want), report.Days)
+	}
+	for i := range want {
+		if report.Days[i] != want[i] {
+			t.Fatalf("day %d: expected %+v but got %+v", i, want[i], report.Days[i])
+		}
+	}
+}
@@ -1,12 +1,19 @@
 package domain
 
+// User logs in with its email, only a bcrypt hash of its password is kept.
+// Users created without a password can't log in.
 type User struct {
-	Id   int64
-	Name string
+	Id           int64  `db:"user_id"`
+	Name         string `db:"user_name"`
+	Email        string `db:"user_email"`
+	PasswordHash string `db:"user_passwordHash" json:"-"`
 }
 type UserRepository interface {
 	All() ([]*User, error)
 	Create(u *User) error
+	// Update leaves the password hash as it is.
+	Update(u *User) error
 	Delete(id int64) error
 	User(id int64) (*User, error)
+	ByEmail(email string) (*User, error)
 }
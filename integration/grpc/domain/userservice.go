@@ -2,4 +2,10 @@ package domain
 
 type UserService interface {
 	Users() ([]*User, error)
+	User(id int64) (*User, error)
+	Create(u *User) error
+	// Register creates a user with a password, the email must not be taken yet.
+	Register(name, email, password string) (*User, error)
+	Update(u *User) error
+	Delete(id int64) error
 }
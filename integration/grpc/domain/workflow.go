@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusOpen       Status = "Open"
+	StatusInProgress Status = "In Progress"
+	StatusInReview   Status = "In Review"
+	StatusResolved   Status = "Resolved"
+	StatusClosed     Status = "Closed"
+)
+
+// Guard is a condition an issue has to meet before a transition.
+type Guard func(issue *Issue) error
+
+// Guards are the guards a Workflow can reference by name.
+var Guards = map[string]Guard{
+	"assigned": func(issue *Issue) error {
+		if issue.OwnerId == 0 && len(issue.Assignees) == 0 {
+			return NewValidationError("the issue must have an assignee")
+		}
+		return nil
+	},
+	"described": func(issue *Issue) error {
+		if issue.Description == "" {
+			return NewValidationError("the issue must have a description")
+		}
+		return nil
+	},
+}
+
+// Transition allows moving an issue from one of the From states to To.
+type Transition struct {
+	From   []Status
+	To     Status
+	Guards []string
+}
+
+// Workflow lists the states of the issues of a project and how they can change.
+// New issues start in Initial.
+type Workflow struct {
+	ProjectId   int64
+	Initial     Status
+	States      []Status
+	Transitions []Transition
+}
+
+type WorkflowRepository interface {
+	// Workflow returns ErrNotFound when the project uses the default workflow.
+	Workflow(projectId int64) (*Workflow, error)
+	Save(w *Workflow) error
+}
+
+// HistoryEntry records a change of an issue field.
+type HistoryEntry struct {
+	Id      int64     `db:"history_id"`
+	IssueId int64     `db:"history_issueId"`
+	UserId  int64     `db:"history_userId"`
+	Field   string    `db:"history_field"`
+	From    string    `db:"history_from"`
+	To      string    `db:"history_to"`
+	At      time.Time `db:"history_at"`
+}
+
+type HistoryRepository interface {
+	Add(entry *HistoryEntry) error
+	ForIssue(issueId int64) ([]*HistoryEntry, error)
+}
+
+func DefaultWorkflow(projectId int64) *Workflow {
+	return &Workflow{
+		ProjectId: projectId,
+		Initial:   StatusOpen,
+		States:    []Status{StatusOpen, StatusInProgress, StatusInReview, StatusResolved, StatusClosed},
+		Transitions: []Transition{
+			{From: []Status{StatusOpen}, To: StatusInProgress, Guards: []string{"assigned"}},
+			{From: []Status{StatusInProgress}, To: StatusInReview},
+			{From: []Status{StatusInReview}, To: StatusInProgress},
+			{From: []Status{StatusInReview}, To: StatusResolved},
+			{From: []Status{StatusOpen, StatusInProgress, StatusInReview, StatusResolved}, To: StatusClosed},
+			{From: []Status{StatusResolved, StatusClosed}, To: StatusOpen},
+		},
+	}
+}
+
+// HasState tells whether s is one of the states of the workflow.
+func (w *Workflow) HasState(s Status) bool {
+	for _, state := range w.States {
+		if state == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the transitions only use declared states and known guards.
+func (w *Workflow) Validate() error {
+	if len(w.States) == 0 {
+		return NewValidationError("a workflow needs at least one state")
+	}
+	if !w.HasState(w.Initial) {
+		return NewValidationError(fmt.Sprintf("initial state %q is not one of the states", w.Initial))
+	}
+	for _, t := range w.Transitions {
+		if !w.HasState(t.To) {
+			return NewValidationError(fmt.Sprintf("transition to unknown state %q", t.To))
+		}
+		for _, from := range t.From {
+			if !w.HasState(from) {
+				return NewValidationError(fmt.Sprintf("transition from unknown state %q", from))
+			}
+		}
+		for _, g := range t.Guards {
+			if _, ok := Guards[g]; !ok {
+				return NewValidationError(fmt.Sprintf("unknown guard %q", g))
+			}
+		}
+	}
+	return nil
+}
+
+// Allow returns an error when the issue can't move to the to state.
+func (w *Workflow) Allow(issue *Issue, to Status) error {
+	for _, t := range w.Transitions {
+		if t.To != to {
+			continue
+		}
+		for _, from := range t.From {
+			if from != issue.Status {
+				continue
+			}
+			for _, g := range t.Guards {
+				if err := Guards[g](issue); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	return NewValidationError(fmt.Sprintf("no transition from %q to %q", issue.Status, to))
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWorkflow_Allow(t *testing.T) {
+	w := DefaultWorkflow(1)
+	tests := []struct {
+		name  string
+		issue Issue
+		to    Status
+		ok    bool
+	}{
+		{"start assigned issue", Issue{Status: StatusOpen, OwnerId: 1}, StatusInProgress, true},
+		{"start issue with assignees", Issue{Status: StatusOpen, Assignees: []int64{2}}, StatusInProgress, true},
+		{"start unassigned issue", Issue{Status: StatusOpen}, StatusInProgress, false},
+		{"skip review", Issue{Status: StatusOpen, OwnerId: 1}, StatusResolved, false},
+		{"reopen", Issue{Status: StatusClosed}, StatusOpen, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := w.Allow(&tt.issue, tt.to)
+			if tt.ok && err != nil {
+				t.Fatalf("expected transition to be allowed but got %v", err)
+			}
+			var verr *ValidationError
+			if !tt.ok && !errors.As(err, &verr) {
+				t.Fatalf("expected a validation error but got %v", err)
+			}
+		})
+	}
+}
+
+func TestWorkflow_Validate(t *testing.T) {
+	if err := DefaultWorkflow(1).Validate(); err != nil {
+		t.Fatalf("default workflow is invalid: %v", err)
+	}
+	w := &Workflow{
+		Initial:     "Todo",
+		States:      []Status{"Todo", "Done"},
+		Transitions: []Transition{{From: []Status{"Todo"}, To: "Done", Guards: []string{"approved"}}},
+	}
+	if err := w.Validate(); err == nil {
+		t.Fatal("expected unknown guard to be rejected")
+	}
+}
@@ -0,0 +1,76 @@
+package interceptor
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/pwera/ddd/domain"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Verifier checks the access tokens like the controller.TokenVerifier of the
+// HTTP servers, auth.RemoteKeys and application.AuthService are both one.
+type Verifier interface {
+	Verify(token string) (*domain.AccessToken, error)
+}
+
+type userKey struct{}
+
+// UnaryAuth lets through the calls carrying a valid "authorization: Bearer"
+// metadata and the calls of the public methods.
+func UnaryAuth(verifier Verifier, public map[string]bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if public[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		ctx, err := authenticate(ctx, verifier)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamAuth(verifier Verifier, public map[string]bool) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if public[info.FullMethod] {
+			return handler(srv, ss)
+		}
+		ctx, err := authenticate(ss.Context(), verifier)
+		if err != nil {
+			return err
+		}
+		return handler(srv, contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// AuthenticatedUser returns the user whose token let the call through.
+func AuthenticatedUser(ctx context.Context) (int64, bool) {
+	userId, ok := ctx.Value(userKey{}).(int64)
+	return userId, ok
+}
+
+func authenticate(ctx context.Context, verifier Verifier) (context.Context, error) {
+	var token string
+	if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 {
+		token = strings.TrimPrefix(values[0], "Bearer ")
+		if token == values[0] {
+			token = ""
+		}
+	}
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "a bearer token is required")
+	}
+	access, err := verifier.Verify(token)
+	if errors.Is(err, domain.ErrUnauthorized) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return context.WithValue(ctx, userKey{}, access.UserId), nil
+}
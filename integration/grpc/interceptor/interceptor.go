@@ -0,0 +1,83 @@
+// Package interceptor holds the interceptors shared by the gRPC servers of the
+// module, Config picks them and keeps their order the same on every server.
+package interceptor
+
+import (
+	"log"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// Config picks the interceptors of a server, the calls always recover from
+// panics and the other interceptors are left out when their field is zero.
+type Config struct {
+	// Logger logs the method, the duration and the code of every call.
+	Logger *log.Logger
+	// Metrics counts the calls and observes their durations per method.
+	Metrics *Metrics
+	// Validate refuses the requests whose Validate method fails.
+	Validate bool
+	// Verifier authenticates the calls with the bearer token of their
+	// authorization metadata, except the calls of the Public methods.
+	Verifier Verifier
+	// Public holds the full method names, like /protocol.User/Login, called
+	// without a token.
+	Public map[string]bool
+}
+
+// ServerOptions chains the interceptors from the outermost, the logging and
+// the metrics see the code the recovery turned a panic into, and the requests
+// are only validated once their call is authenticated.
+func (c Config) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(c.unary()...),
+		grpc.ChainStreamInterceptor(c.stream()...),
+	}
+}
+
+func (c Config) unary() []grpc.UnaryServerInterceptor {
+	var chain []grpc.UnaryServerInterceptor
+	if c.Logger != nil {
+		chain = append(chain, UnaryLogging(c.Logger))
+	}
+	if c.Metrics != nil {
+		chain = append(chain, c.Metrics.Unary)
+	}
+	chain = append(chain, UnaryRecovery)
+	if c.Verifier != nil {
+		chain = append(chain, UnaryAuth(c.Verifier, c.Public))
+	}
+	if c.Validate {
+		chain = append(chain, UnaryValidation)
+	}
+	return chain
+}
+
+func (c Config) stream() []grpc.StreamServerInterceptor {
+	var chain []grpc.StreamServerInterceptor
+	if c.Logger != nil {
+		chain = append(chain, StreamLogging(c.Logger))
+	}
+	if c.Metrics != nil {
+		chain = append(chain, c.Metrics.Stream)
+	}
+	chain = append(chain, StreamRecovery)
+	if c.Verifier != nil {
+		chain = append(chain, StreamAuth(c.Verifier, c.Public))
+	}
+	if c.Validate {
+		chain = append(chain, StreamValidation)
+	}
+	return chain
+}
+
+// contextStream replaces the context of a stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,108 @@
+package interceptor
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/pwera/ddd/domain"
+	"github.com/pwera/ddd/protocol/protocol"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type tokens map[string]int64
+
+func (t tokens) Verify(token string) (*domain.AccessToken, error) {
+	userId, ok := t[token]
+	if !ok {
+		return nil, fmt.Errorf("unknown token: %w", domain.ErrUnauthorized)
+	}
+	return &domain.AccessToken{Id: token, UserId: userId}, nil
+}
+
+// userServer panics on Login and answers RefreshToken with the authenticated user.
+type userServer struct {
+	protocol.UnimplementedUserServer
+}
+
+func (userServer) Login(ctx context.Context, req *protocol.LoginRequest) (*protocol.TokenResponse, error) {
+	panic("login")
+}
+
+func (userServer) RefreshToken(ctx context.Context, req *protocol.RefreshTokenRequest) (*protocol.TokenResponse, error) {
+	userId, _ := AuthenticatedUser(ctx)
+	return &protocol.TokenResponse{AccessToken: fmt.Sprint(userId)}, nil
+}
+
+func TestConfig_ServerOptions(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+	config := Config{
+		Metrics:  metrics,
+		Validate: true,
+		Verifier: tokens{"good": 7},
+		Public:   map[string]bool{protocol.User_Login_FullMethodName: true},
+	}
+	lis := bufconn.Listen(1 << 16)
+	server := grpc.NewServer(config.ServerOptions()...)
+	protocol.RegisterUserServer(server, userServer{})
+	go server.Serve(lis)
+	defer server.Stop()
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := protocol.NewUserClient(conn)
+	bearer := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	_, err = client.Login(context.Background(), &protocol.LoginRequest{Email: "ann@example.com", Password: "secret"})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected the panic to be Internal but got %v", err)
+	}
+	_, err = client.Login(context.Background(), &protocol.LoginRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected an invalid public call to be InvalidArgument but got %v", err)
+	}
+	for name, ctx := range map[string]context.Context{
+		"no token":  context.Background(),
+		"bad token": bearer("bad"),
+	} {
+		_, err := client.RefreshToken(ctx, &protocol.RefreshTokenRequest{RefreshToken: "r"})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("%s: expected Unauthenticated but got %v", name, err)
+		}
+	}
+	_, err = client.RefreshToken(bearer("bad"), &protocol.RefreshTokenRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected the token checked before the request but got %v", err)
+	}
+	resp, err := client.RefreshToken(bearer("good"), &protocol.RefreshTokenRequest{RefreshToken: "r"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.AccessToken != "7" {
+		t.Fatalf("expected the handler to see user 7 but got %q", resp.AccessToken)
+	}
+
+	for code, want := range map[codes.Code]float64{codes.Internal: 1, codes.InvalidArgument: 1} {
+		got := testutil.ToFloat64(metrics.handled.WithLabelValues(protocol.User_Login_FullMethodName, "unary", code.String()))
+		if got != want {
+			t.Fatalf("expected %v Login calls ending with %s but got %v", want, code, got)
+		}
+	}
+	if got := testutil.ToFloat64(metrics.handled.WithLabelValues(protocol.User_RefreshToken_FullMethodName, "unary", "Unauthenticated")); got != 3 {
+		t.Fatalf("expected 3 unauthenticated RefreshToken calls but got %v", got)
+	}
+}
@@ -0,0 +1,40 @@
+package interceptor
+
+import (
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryLogging logs every call as key=value pairs, the failed ones with their
+// error.
+func UnaryLogging(logger *log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(logger, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func StreamLogging(logger *log.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(logger, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(logger *log.Logger, method string, start time.Time, err error) {
+	s := status.Convert(err)
+	if s.Code() == codes.OK {
+		logger.Printf("method=%s duration=%s code=%s", method, time.Since(start), s.Code())
+		return
+	}
+	logger.Printf("method=%s duration=%s code=%s error=%q", method, time.Since(start), s.Code(), s.Message())
+}
@@ -0,0 +1,64 @@
+package interceptor
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics counts the handled calls by method and code and observes how long
+// they took by method.
+type Metrics struct {
+	handled  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewMetrics registers the metrics, a registerer takes a single Metrics.
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Number of gRPC calls completed by the server.",
+		}, []string{"grpc_method", "grpc_type", "grpc_code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Duration of the gRPC calls completed by the server.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"grpc_method", "grpc_type"}),
+	}
+	registerer.MustRegister(m.handled, m.duration)
+	return m
+}
+
+func (m *Metrics) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	m.observe(info.FullMethod, "unary", start, err)
+	return resp, err
+}
+
+func (m *Metrics) Stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	m.observe(info.FullMethod, streamType(info), start, err)
+	return err
+}
+
+func (m *Metrics) observe(method, callType string, start time.Time, err error) {
+	m.handled.WithLabelValues(method, callType, status.Code(err).String()).Inc()
+	m.duration.WithLabelValues(method, callType).Observe(time.Since(start).Seconds())
+}
+
+func streamType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return "bidi_stream"
+	case info.IsClientStream:
+		return "client_stream"
+	default:
+		return "server_stream"
+	}
+}
@@ -0,0 +1,49 @@
+package interceptor
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Validator is implemented by the request messages checking their fields.
+type Validator interface {
+	Validate() error
+}
+
+// UnaryValidation refuses the requests failing their Validate method with
+// InvalidArgument, the messages without one pass.
+func UnaryValidation(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamValidation validates every message the handler receives.
+func StreamValidation(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, validatingStream{ss})
+}
+
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+func (s validatingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validate(m)
+}
+
+func validate(m interface{}) error {
+	v, ok := m.(Validator)
+	if !ok {
+		return nil
+	}
+	if err := v.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
@@ -1,52 +1,332 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/jmoiron/sqlx"
 	"github.com/pwera/ddd/application"
+	"github.com/pwera/ddd/auth"
 	"github.com/pwera/ddd/controller"
 	"github.com/pwera/ddd/domain"
+	"github.com/pwera/ddd/persistence"
+	"github.com/pwera/ddd/persistence/blob"
 	"github.com/pwera/ddd/persistence/db"
-	"github.com/pwera/ddd/persistence/memory"
 	"github.com/pwera/ddd/protocol/protocol"
+	"github.com/pwera/ddd/readiness"
+	"github.com/pwera/ddd/tracker"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 )
 
 const serverAddr = "127.0.0.1:10000"
 
+var (
+	dsn             = flag.String("dsn", db.SharedDSN, "SQLite data source name, shared with the auth server that serves /v1, :memory: keeps the data for this run only")
+	userStore       = flag.String("user-store", persistence.UserStoreSQLite, "where users are kept: memory or sqlite")
+	sla             = flag.String("sla", domain.DefaultSLA().String(), "time an issue of each priority may stay unresolved, like High=24h,Medium=72h")
+	blobDir         = flag.String("blob-dir", filepath.Join(os.TempDir(), "ddd-attachments"), "directory where the content of attachments is kept")
+	maxAttachment   = flag.Int64("attachment-max-size", application.DefaultAttachmentMaxSize, "largest attachment accepted, in bytes")
+	attachTypes     = flag.String("attachment-types", strings.Join(application.DefaultAttachmentTypes, ","), "content types accepted for attachments, type/* accepts a whole type")
+	authURL         = flag.String("auth-url", "http://localhost:8091", "auth server publishing the keys that verify access tokens")
+	authRefresh     = flag.Duration("auth-refresh", 30*time.Second, "how often the keys and revoked tokens are fetched from the auth server")
+	requireAuth     = flag.Bool("require-auth", true, "refuse requests without a valid access token, except to log in and register")
+	schemaVersion   = flag.Int("schema-version", -1, "migrate the database to this version and exit, -1 to only apply pending migrations")
+	healthInterval  = flag.Duration("health-interval", 5*time.Second, "how often the database and the auth server are checked")
+	healthTimeout   = flag.Duration("health-timeout", time.Second, "how long a readiness check may take")
+	shutdownTimeout = flag.Duration("shutdown-timeout", 15*time.Second, "how long the requests in flight may take to finish after SIGTERM")
+	shutdownDelay   = flag.Duration("shutdown-delay", 2*time.Second, "how long NOT_SERVING is reported after SIGTERM before new requests are refused")
+)
+
 func main() {
+	flag.Parse()
+	if *schemaVersion >= 0 {
+		// Open would first migrate up to the latest version
+		conn, err := db.Connect(*dsn)
+		if err != nil {
+			log.Fatalf("fail to open database: %v", err)
+		}
+		migrateTo(conn, *schemaVersion)
+		conn.Close()
+		return
+	}
+	conn, err := db.Open(*dsn)
+	if err != nil {
+		log.Fatalf("fail to open database: %v", err)
+	}
+
+	issueSLA, err := domain.ParseSLA(*sla)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	blobStore, err := blob.NewFileStore(*blobDir)
+	if err != nil {
+		log.Fatalf("fail to open blob store: %v", err)
+	}
+
 	var opts []grpc.DialOption
 	opts = append(opts, grpc.WithInsecure())
 	dial, err := grpc.Dial(serverAddr, opts...)
 	if err != nil {
 		log.Fatalf("fail to dial: %v", err)
 	}
-	defer dial.Close()
 	userClient := protocol.NewUserClient(dial)
-	_, _ = userClient.SubmitNewUser(context.Background(), nil)
-	userRepo := memory.NewUserRepository()
-	issueRepo := db.NewIssueRepository()
-	userService := application.UserService{UserRepository: userRepo}
-	issueService := application.IssueService{IssueRepository: issueRepo}
+	userRepo, err := persistence.NewUserRepository(*userStore, conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	services := tracker.NewServices(conn, userRepo, blobStore, issueSLA)
+	userService, projectService, issueService := services.Users, services.Projects, services.Issues
+	activityService, notificationService, events := services.Activity, services.Notifications, services.Events
+	issueRepo := issueService.IssueRepository
+	projectRepo := issueService.ProjectRepository
+	labelRepo := issueService.LabelRepository
+	fieldRepo := issueService.FieldRepository
+	participantRepo := issueService.ParticipantRepository
+	sprintRepo := issueService.SprintRepository
+	attachmentRepo := issueService.AttachmentRepository
+	sprintService := application.SprintService{
+		SprintRepository:  sprintRepo,
+		ProjectRepository: projectRepo,
+		IssueRepository:   issueRepo,
+		EventRepository:   activityService.EventRepository,
+	}
+	savedQueryService := application.SavedQueryService{
+		SavedQueryRepository: db.NewSavedQueryRepository(conn),
+		UserRepository:       userRepo,
+		IssueService:         issueService,
+	}
+	labelService := application.LabelService{
+		LabelRepository:   labelRepo,
+		ProjectRepository: projectRepo,
+		IssueRepository:   issueRepo,
+	}
+	fieldService := application.FieldService{
+		FieldRepository:   fieldRepo,
+		ProjectRepository: projectRepo,
+		IssueRepository:   issueRepo,
+		UserRepository:    userRepo,
+	}
+	linkService := application.LinkService{
+		LinkRepository:    db.NewLinkRepository(conn),
+		IssueRepository:   issueRepo,
+		ProjectRepository: projectRepo,
+	}
+	commentService := application.CommentService{
+		CommentRepository:     db.NewCommentRepository(conn),
+		IssueRepository:       issueRepo,
+		UserRepository:        userRepo,
+		ParticipantRepository: participantRepo,
+		Events:                events,
+	}
+	participantService := application.ParticipantService{
+		ParticipantRepository: participantRepo,
+		IssueRepository:       issueRepo,
+		UserRepository:        userRepo,
+		Events:                events,
+	}
+	attachmentService := application.AttachmentService{
+		AttachmentRepository: attachmentRepo,
+		IssueRepository:      issueRepo,
+		BlobStore:            blobStore,
+		MaxSize:              *maxAttachment,
+		AllowedTypes:         strings.Split(*attachTypes, ","),
+	}
+	if err := attachmentService.RemoveUnused(); err != nil {
+		log.Printf("fail to remove unused attachment contents: %v", err)
+	}
 	userController := controller.UserController{UserService: userService}
+	projectController := controller.ProjectController{ProjectService: projectService}
 	issueController := controller.IssueController{IssueService: issueService}
+	commentController := controller.CommentController{CommentService: commentService}
+	labelController := controller.LabelController{LabelService: labelService}
+	fieldController := controller.FieldController{FieldService: fieldService}
+	participantController := controller.ParticipantController{ParticipantService: participantService}
+	notificationController := controller.NotificationController{NotificationService: notificationService}
+	savedQueryController := controller.SavedQueryController{SavedQueryService: savedQueryService}
+	activityController := controller.ActivityController{ActivityService: activityService}
+	linkController := controller.LinkController{LinkService: linkService}
+	sprintController := controller.SprintController{SprintService: sprintService}
+	attachmentController := controller.AttachmentController{AttachmentService: attachmentService, MaxSize: *maxAttachment}
 	authorizationController := controller.AuthorizationController{
 		Client: userClient,
 	}
+	gateway := runtime.NewServeMux(runtime.WithErrorHandler(controller.GatewayError))
+	if err := protocol.RegisterIssueTrackerHandler(context.Background(), gateway, dial); err != nil {
+		log.Fatalf("fail to register the tracker gateway: %v", err)
+	}
+	if err := protocol.RegisterUserHandler(context.Background(), gateway, dial); err != nil {
+		log.Fatalf("fail to register the user gateway: %v", err)
+	}
+	// The /api routes need the database, the gateway serving /v1 needs the auth server too.
+	healthServer := health.NewServer()
+	checker := readiness.Checker{
+		Server: healthServer,
+		Services: map[string][]readiness.Check{
+			"api":     {conn.PingContext},
+			"gateway": {conn.PingContext, readiness.Remote(dial, "")},
+		},
+		Timeout: *healthTimeout,
+	}
+	checker.Update(context.Background())
 	prepareUsers(userService)
+	prepareProjects(projectService)
 	prepareIssues(issueService)
-	mux := http.NewServeMux()
-	mux.HandleFunc("/api/users", userController.List)
-	mux.HandleFunc("/api/issues", issueController.List)
-	mux.HandleFunc("/api/register", authorizationController.Register)
+	r := mux.NewRouter()
+	r.HandleFunc("/health", checker.Handler).Methods(http.MethodGet)
+	r.HandleFunc("/api/users", userController.List).Methods(http.MethodGet)
+	r.HandleFunc("/api/users", userController.Create).Methods(http.MethodPost)
+	r.HandleFunc("/api/users/{id:[0-9]+}", userController.Show).Methods(http.MethodGet)
+	r.HandleFunc("/api/users/{id:[0-9]+}", userController.Update).Methods(http.MethodPut)
+	r.HandleFunc("/api/users/{id:[0-9]+}", userController.Delete).Methods(http.MethodDelete)
+	r.HandleFunc("/api/users/{id:[0-9]+}/notifications", notificationController.List).Methods(http.MethodGet)
+	r.HandleFunc("/api/users/{id:[0-9]+}/notifications/read", notificationController.MarkAllRead).Methods(http.MethodPost)
+	r.HandleFunc("/api/users/{id:[0-9]+}/notifications/{notificationId:[0-9]+}/read", notificationController.MarkRead).Methods(http.MethodPost)
+	r.HandleFunc("/api/users/{id:[0-9]+}/queries", savedQueryController.List).Methods(http.MethodGet)
+	r.HandleFunc("/api/users/{id:[0-9]+}/queries", savedQueryController.Create).Methods(http.MethodPost)
+	r.HandleFunc("/api/users/{id:[0-9]+}/queries/{queryId:[0-9]+}", savedQueryController.Delete).Methods(http.MethodDelete)
+	r.HandleFunc("/api/users/{id:[0-9]+}/queries/{queryId:[0-9]+}/issues", savedQueryController.Issues).Methods(http.MethodGet)
+	r.HandleFunc("/api/projects", projectController.List).Methods(http.MethodGet)
+	r.HandleFunc("/api/projects", projectController.Create).Methods(http.MethodPost)
+	r.HandleFunc("/api/projects/{id:[0-9]+}", projectController.Show).Methods(http.MethodGet)
+	r.HandleFunc("/api/projects/{id:[0-9]+}", projectController.Delete).Methods(http.MethodDelete)
+	r.HandleFunc("/api/projects/{id:[0-9]+}/workflow", projectController.Workflow).Methods(http.MethodGet)
+	r.HandleFunc("/api/projects/{id:[0-9]+}/workflow", projectController.SetWorkflow).Methods(http.MethodPut)
+	r.HandleFunc("/api/projects/{id:[0-9]+}/labels", labelController.List).Methods(http.MethodGet)
+	r.HandleFunc("/api/projects/{id:[0-9]+}/labels", labelController.Create).Methods(http.MethodPost)
+	r.HandleFunc("/api/projects/{id:[0-9]+}/labels/{labelId:[0-9]+}", labelController.Delete).Methods(http.MethodDelete)
+	r.HandleFunc("/api/projects/{id:[0-9]+}/fields", fieldController.List).Methods(http.MethodGet)
+	r.HandleFunc("/api/projects/{id:[0-9]+}/fields", fieldController.Create).Methods(http.MethodPost)
+	r.HandleFunc("/api/projects/{id:[0-9]+}/fields/{fieldId:[0-9]+}", fieldController.Delete).Methods(http.MethodDelete)
+	r.HandleFunc("/api/projects/{id:[0-9]+}/sprints", sprintController.List).Methods(http.MethodGet)
+	r.HandleFunc("/api/projects/{id:[0-9]+}/sprints", sprintController.Create).Methods(http.MethodPost)
+	r.HandleFunc("/api/projects/{id:[0-9]+}/sprints/{sprintId:[0-9]+}", sprintController.Delete).Methods(http.MethodDelete)
+	r.HandleFunc("/api/projects/{id:[0-9]+}/sprints/{sprintId:[0-9]+}/burndown", sprintController.Report).Methods(http.MethodGet)
+	r.HandleFunc("/api/projects/{id:[0-9]+}/dependency-graph", linkController.DependencyGraph).Methods(http.MethodGet)
+	r.HandleFunc("/api/issues", issueController.List).Methods(http.MethodGet)
+	r.HandleFunc("/api/issues", issueController.Create).Methods(http.MethodPost)
+	r.HandleFunc("/api/issues/breaching", issueController.Breaching).Methods(http.MethodGet)
+	r.HandleFunc("/api/issues/{id:[0-9]+}", issueController.Show).Methods(http.MethodGet)
+	r.HandleFunc("/api/issues/{id:[0-9]+}", issueController.Update).Methods(http.MethodPut)
+	r.HandleFunc("/api/issues/{id:[0-9]+}", issueController.Patch).Methods(http.MethodPatch)
+	r.HandleFunc("/api/issues/{id:[0-9]+}", issueController.Delete).Methods(http.MethodDelete)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/transitions", issueController.Transition).Methods(http.MethodPost)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/history", issueController.History).Methods(http.MethodGet)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/activity", activityController.List).Methods(http.MethodGet)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/activity/check", activityController.Check).Methods(http.MethodGet)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/links", linkController.List).Methods(http.MethodGet)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/links", linkController.Create).Methods(http.MethodPost)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/links/{linkId:[0-9]+}", linkController.Delete).Methods(http.MethodDelete)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/blockers", linkController.Blockers).Methods(http.MethodGet)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/attachments", attachmentController.List).Methods(http.MethodGet)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/attachments", attachmentController.Create).Methods(http.MethodPost)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/attachments/{attachmentId:[0-9]+}", attachmentController.Download).Methods(http.MethodGet)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/attachments/{attachmentId:[0-9]+}", attachmentController.Delete).Methods(http.MethodDelete)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/labels/{labelId:[0-9]+}", labelController.Attach).Methods(http.MethodPut)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/labels/{labelId:[0-9]+}", labelController.Detach).Methods(http.MethodDelete)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/fields", fieldController.Set).Methods(http.MethodPatch)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/assignees/{userId:[0-9]+}", participantController.Assign).Methods(http.MethodPut)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/assignees/{userId:[0-9]+}", participantController.Unassign).Methods(http.MethodDelete)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/watchers/{userId:[0-9]+}", participantController.Watch).Methods(http.MethodPut)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/watchers/{userId:[0-9]+}", participantController.Unwatch).Methods(http.MethodDelete)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/comments", commentController.List).Methods(http.MethodGet)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/comments", commentController.Create).Methods(http.MethodPost)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/comments/{commentId:[0-9]+}", commentController.Update).Methods(http.MethodPut)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/comments/{commentId:[0-9]+}", commentController.Delete).Methods(http.MethodDelete)
+	r.HandleFunc("/api/issues/{id:[0-9]+}/comments/{commentId:[0-9]+}/revisions", commentController.Revisions).Methods(http.MethodGet)
+	r.HandleFunc("/api/register", authorizationController.Register).Methods(http.MethodPost)
+	// The gateway serves the gRPC services as REST, see the google.api.http options in protocol.
+	r.PathPrefix("/v1/").Handler(gateway)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *requireAuth {
+		keys := auth.NewRemoteKeys(*authURL)
+		go keys.RefreshEvery(ctx, *authRefresh)
+		authenticator := controller.Authenticator{
+			Verifier: keys,
+			Public: map[string]bool{
+				"/health":           true,
+				"/api/register":     true,
+				"/v1/register":      true,
+				"/v1/login":         true,
+				"/v1/token:refresh": true,
+				"/v1/logout":        true,
+			},
+		}
+		r.Use(authenticator.Middleware)
+	}
 
-	_ = http.ListenAndServe(":8090", mux)
+	server := &http.Server{Addr: ":8090", Handler: r}
+	go checker.Run(ctx, *healthInterval)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		log.Print("shutting down")
+	case serveErr = <-errs:
+	}
+	stop()
+	// The clients watching the health see NOT_SERVING for the shutdown delay
+	// before the server stops taking requests.
+	healthServer.Shutdown()
+	time.Sleep(*shutdownDelay)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("fail to drain the HTTP server: %v", err)
+	}
+	dial.Close()
+	if err := conn.Close(); err != nil {
+		log.Printf("fail to close database: %v", err)
+	}
+	if serveErr != nil {
+		log.Fatalf("failed to serve: %v", serveErr)
+	}
+}
+
+func migrateTo(conn *sqlx.DB, version int) {
+	migrator, err := db.NewMigrator(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := migrator.To(version); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("database is at schema version %d", version)
+}
 
+func prepareProjects(projectService application.ProjectService) {
+	if projects, err := projectService.Projects(); err != nil || len(projects) > 0 {
+		return
+	}
+	err := projectService.Create(&domain.Project{
+		Name:        "Project",
+		OwnerId:     1,
+		Description: "??",
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
 }
 
 func prepareIssues(issueService application.IssueService) {
+	if issues, err := issueService.Issues(domain.IssueFilter{}); err != nil || len(issues) > 0 {
+		return
+	}
 	issue := domain.Issue{
 		Title:       "Title",
 		Priority:    domain.PriorityLow,
@@ -61,7 +341,13 @@ func prepareIssues(issueService application.IssueService) {
 }
 
 func prepareUsers(userService application.UserService) {
+	if users, err := userService.Users(); err != nil || len(users) > 0 {
+		return
+	}
 	for i := 0; i < 10; i += 1 {
-		_ = userService.Create(&domain.User{Name: fmt.Sprintf("User_%d", i)})
+		_ = userService.Create(&domain.User{
+			Name:  fmt.Sprintf("User_%d", i),
+			Email: fmt.Sprintf("user_%d@example.com", i),
+		})
 	}
 }
@@ -0,0 +1,132 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/pwera/ddd/domain"
+)
+
+var digestPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// FileStore keeps every blob in a file named after its SHA-256 under dir,
+// spread over sub directories named after the first two hex digits.
+type FileStore struct {
+	dir string
+
+	mu   sync.Mutex
+	held map[string]int
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir, held: map[string]int{}}, nil
+}
+
+func (s *FileStore) path(digest string) (string, error) {
+	if !digestPattern.MatchString(digest) {
+		return "", fmt.Errorf("invalid blob digest %q", digest)
+	}
+	return filepath.Join(s.dir, digest[:2], digest), nil
+}
+
+// Put writes r to a temporary file while hashing it and then moves the file
+// in place, unless the same content is already stored. The blob is held until
+// Unhold.
+func (s *FileStore) Put(r io.Reader) (string, int64, error) {
+	tmp, err := os.CreateTemp(s.dir, "upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	digest := hex.EncodeToString(hash.Sum(nil))
+	path, err := s.path(digest)
+	if err != nil {
+		return "", 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := os.Stat(path); err != nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return "", 0, err
+		}
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			return "", 0, err
+		}
+	}
+	s.held[digest]++
+	return digest, size, nil
+}
+
+func (s *FileStore) Unhold(digest string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.held[digest]--; s.held[digest] <= 0 {
+		delete(s.held, digest)
+	}
+}
+
+func (s *FileStore) Open(digest string) (io.ReadSeekCloser, error) {
+	path, err := s.path(digest)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("blob %s: %w", digest, domain.ErrNotFound)
+	}
+	return f, err
+}
+
+// Delete leaves a held blob alone.
+func (s *FileStore) Delete(digest string) error {
+	path, err := s.path(digest)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.held[digest] > 0 {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Digests skips the files that aren't blobs, like unfinished uploads.
+func (s *FileStore) Digests() ([]string, error) {
+	var digests []string
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if !d.IsDir() && digestPattern.MatchString(name) && filepath.Base(filepath.Dir(path)) == name[:2] {
+			digests = append(digests, name)
+		}
+		return nil
+	})
+	return digests, err
+}
@@ -0,0 +1,60 @@
+package blob
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pwera/ddd/domain"
+)
+
+func TestFileStore_Deduplicates(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, size, err := store.Put(strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 5 || first != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Fatalf("expected the SHA-256 of hello but got %s, %d bytes", first, size)
+	}
+	second, _, err := store.Put(strings.NewReader("hello"))
+	if err != nil || second != first {
+		t.Fatalf("expected the same digest but got %s, %v", second, err)
+	}
+
+	f, err := store.Open(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Seek(1, io.SeekStart)
+	content, _ := io.ReadAll(f)
+	f.Close()
+	if string(content) != "ello" {
+		t.Fatalf("expected to read from the offset but got %q", content)
+	}
+
+	// both uploads hold the content until they are done with it
+	store.Unhold(first)
+	if err := store.Delete(first); err != nil {
+		t.Fatal(err)
+	}
+	if f, err := store.Open(first); err != nil {
+		t.Fatalf("expected a held blob to survive Delete but got %v", err)
+	} else {
+		f.Close()
+	}
+	store.Unhold(second)
+	if err := store.Delete(first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Open(first); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound but got %v", err)
+	}
+	if _, err := store.Open("../../etc/passwd"); err == nil {
+		t.Fatal("expected a path to be refused as a digest")
+	}
+}
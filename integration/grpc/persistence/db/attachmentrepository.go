@@ -0,0 +1,73 @@
+package db
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectAttachment       = "SELECT * FROM attachments WHERE attachment_id=?"
+	querySelectIssueAttachments = "SELECT * FROM attachments WHERE attachment_issueId=? ORDER BY attachment_id"
+	queryInsertAttachment       = "INSERT INTO attachments (attachment_issueId, attachment_name, attachment_contentType, attachment_size, attachment_digest, attachment_createdAt) VALUES (?, ?, ?, ?, ?, ?)"
+	queryDeleteAttachment       = "DELETE FROM attachments WHERE attachment_id=?"
+	queryCountDigest            = "SELECT COUNT(*) FROM attachments WHERE attachment_digest=?"
+)
+
+type AttachmentRepository struct {
+	db *sqlx.DB
+}
+
+func NewAttachmentRepository(db *sqlx.DB) *AttachmentRepository {
+	return &AttachmentRepository{
+		db: db,
+	}
+}
+
+func (r *AttachmentRepository) GetById(id int64) (*domain.Attachment, error) {
+	var a domain.Attachment
+	err := r.db.Get(&a, querySelectAttachment, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *AttachmentRepository) ForIssue(issueId int64) ([]*domain.Attachment, error) {
+	attachments := make([]*domain.Attachment, 0)
+	if err := r.db.Select(&attachments, querySelectIssueAttachments, issueId); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *AttachmentRepository) Create(a *domain.Attachment) error {
+	res, err := r.db.Exec(queryInsertAttachment, a.IssueId, a.Name, a.ContentType, a.Size, a.Digest, a.CreatedAt)
+	if err != nil {
+		return err
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	a.Id = lastId
+	return nil
+}
+
+func (r *AttachmentRepository) Delete(id int64) error {
+	res, err := r.db.Exec(queryDeleteAttachment, id)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func (r *AttachmentRepository) CountDigest(digest string) (int, error) {
+	var n int
+	err := r.db.Get(&n, queryCountDigest, digest)
+	return n, err
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectComment        = "SELECT * FROM comments WHERE comment_id=?"
+	querySelectIssueComments  = "SELECT * FROM comments WHERE comment_issueId=? ORDER BY comment_id LIMIT ? OFFSET ?"
+	queryCountIssueComments   = "SELECT COUNT(*) FROM comments WHERE comment_issueId=?"
+	queryInsertComment        = "INSERT INTO comments (comment_issueId, comment_parentId, comment_authorId, comment_body, comment_createdAt, comment_updatedAt) VALUES (?, ?, ?, ?, ?, ?)"
+	queryInsertRevision       = "INSERT INTO comment_revisions (revision_commentId, revision_body, revision_editedAt) SELECT comment_id, comment_body, ? FROM comments WHERE comment_id=?"
+	queryUpdateComment        = "UPDATE comments SET comment_body=?, comment_updatedAt=? WHERE comment_id=?"
+	queryDeleteComment        = "UPDATE comments SET comment_body='', comment_deleted=1, comment_updatedAt=? WHERE comment_id=?"
+	querySelectCommentHistory = "SELECT * FROM comment_revisions WHERE revision_commentId=? ORDER BY revision_id"
+)
+
+type CommentRepository struct {
+	db *sqlx.DB
+}
+
+func NewCommentRepository(db *sqlx.DB) *CommentRepository {
+	return &CommentRepository{
+		db: db,
+	}
+}
+
+func (r *CommentRepository) GetById(id int64) (*domain.Comment, error) {
+	var c domain.Comment
+	err := r.db.Get(&c, querySelectComment, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *CommentRepository) ForIssue(issueId int64, limit, offset int) ([]*domain.Comment, error) {
+	comments := make([]*domain.Comment, 0)
+	err := r.db.Select(&comments, querySelectIssueComments, issueId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *CommentRepository) Count(issueId int64) (int, error) {
+	var n int
+	err := r.db.Get(&n, queryCountIssueComments, issueId)
+	return n, err
+}
+
+func (r *CommentRepository) Create(c *domain.Comment, events ...*domain.IssueEvent) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(queryInsertComment, c.IssueId, c.ParentId, c.AuthorId, c.Body, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err := appendEvents(tx, events); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	c.Id = lastId
+	return nil
+}
+
+// Update copies the stored body to comment_revisions before overwriting it.
+func (r *CommentRepository) Update(c *domain.Comment, events ...*domain.IssueEvent) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(queryInsertRevision, c.UpdatedAt, c.Id); err != nil {
+		return err
+	}
+	res, err := tx.Exec(queryUpdateComment, c.Body, c.UpdatedAt, c.Id)
+	if err != nil {
+		return err
+	}
+	if err := expectOneRow(res); err != nil {
+		return err
+	}
+	if err := appendEvents(tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *CommentRepository) Delete(id int64, events ...*domain.IssueEvent) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(queryDeleteComment, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	if err := expectOneRow(res); err != nil {
+		return err
+	}
+	if err := appendEvents(tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *CommentRepository) Revisions(commentId int64) ([]*domain.CommentRevision, error) {
+	revisions := make([]*domain.CommentRevision, 0)
+	err := r.db.Select(&revisions, querySelectCommentHistory, commentId)
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+)
+
+func TestCommentRepository_UpdateKeepsRevisions(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewCommentRepository(conn)
+
+	now := time.Now().UTC()
+	c := &domain.Comment{IssueId: 1, AuthorId: 1, Body: "# first\n", CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(c); err != nil {
+		t.Fatal(err)
+	}
+	c.Body = "second"
+	if err := repo.Update(c); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := repo.Revisions(c.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 1 || revisions[0].Body != "# first\n" {
+		t.Fatalf("expected the first body in the revisions but got %+v", revisions)
+	}
+
+	if err := repo.Delete(c.Id); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := repo.GetById(c.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.Deleted || stored.Body != "" {
+		t.Fatalf("expected a blanked comment but got %+v", stored)
+	}
+	if err := repo.Update(&domain.Comment{Id: 42, Body: "x"}); err != domain.ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got %v", err)
+	}
+}
+
+func TestCommentRepository_AppendsEvents(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewCommentRepository(conn)
+
+	now := time.Now().UTC()
+	c := &domain.Comment{IssueId: 1, AuthorId: 1, Body: "first", CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(c, &domain.IssueEvent{Type: domain.EventIssueCommented, IssueId: 1, At: now}); err != nil {
+		t.Fatal(err)
+	}
+	c.Body = "second"
+	if err := repo.Update(c, &domain.IssueEvent{Type: domain.EventCommentEdited, IssueId: 1, At: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(c.Id, &domain.IssueEvent{Type: domain.EventCommentDeleted, IssueId: 1, At: now}); err != nil {
+		t.Fatal(err)
+	}
+	// the event of a change that fails is not kept
+	if err := repo.Delete(42, &domain.IssueEvent{Type: domain.EventCommentDeleted, IssueId: 1, At: now}); err != domain.ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got %v", err)
+	}
+
+	events, err := NewEventRepository(conn).ForIssue(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.EventType{domain.EventIssueCommented, domain.EventCommentEdited, domain.EventCommentDeleted}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events but got %+v", len(want), events)
+	}
+	for n, e := range events {
+		if e.Type != want[n] {
+			t.Fatalf("expected a %s event but got %+v", want[n], e)
+		}
+	}
+}
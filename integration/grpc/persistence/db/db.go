@@ -0,0 +1,53 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const driver = "sqlite3"
+
+// DefaultDSN keeps everything in memory, use a file name to keep the data.
+const DefaultDSN = ":memory:"
+
+// SharedDSN is the file the tracker and the auth server both default to, so
+// that /api and /v1 serve the same data. Each waits for the writes of the other
+// instead of failing with "database is locked".
+var SharedDSN = filepath.Join(os.TempDir(), "ddd.db") + "?_busy_timeout=5000&_journal_mode=WAL"
+
+// Open connects to the SQLite database behind dsn and migrates it
+// to the latest schema version.
+func Open(dsn string) (*sqlx.DB, error) {
+	db, err := Connect(dsn)
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := migrator.Up(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Connect connects to the SQLite database behind dsn and leaves its schema
+// alone, for a Migrator to move it to a given version.
+func Connect(dsn string) (*sqlx.DB, error) {
+	db, err := sqlx.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(dsn, ":memory:") {
+		// every connection to :memory: is a new empty database
+		db.SetMaxOpenConns(1)
+	}
+	return db, nil
+}
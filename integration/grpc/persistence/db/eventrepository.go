@@ -0,0 +1,82 @@
+package db
+
+import (
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectIssueEvents = "SELECT * FROM issue_events WHERE event_issueId=? ORDER BY event_type<>'issue.created', event_id"
+	querySelectEventsSince = "SELECT * FROM issue_events WHERE event_id>? ORDER BY event_id LIMIT ?"
+	querySelectLastEventId = "SELECT COALESCE(MAX(event_id), 0) FROM issue_events"
+	queryInsertIssueEvent  = "INSERT INTO issue_events (event_type, event_issueId, event_actorId, event_field, event_from, event_to, event_message, event_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	// the creation events hold the new issue as JSON, where SprintId is followed by Points
+	querySelectInSprint = `SELECT DISTINCT event_issueId FROM issue_events
+WHERE (event_field='sprint' AND (event_from=?1 OR event_to=?1))
+OR (event_type='issue.created' AND event_to LIKE '%"SprintId":' || ?1 || ',%')
+ORDER BY event_issueId`
+)
+
+// EventRepository stores the issue events in issue_events, the table refuses
+// updates and deletes.
+type EventRepository struct {
+	db *sqlx.DB
+}
+
+func NewEventRepository(db *sqlx.DB) *EventRepository {
+	return &EventRepository{
+		db: db,
+	}
+}
+
+func (r *EventRepository) Append(e *domain.IssueEvent) error {
+	return appendEvents(r.db, []*domain.IssueEvent{e})
+}
+
+// appendEvents lets the repositories append events in their own transactions.
+func appendEvents(db sqlx.Execer, events []*domain.IssueEvent) error {
+	for _, e := range events {
+		res, err := db.Exec(queryInsertIssueEvent, e.Type, e.IssueId, e.ActorId, e.Field, e.From, e.To, e.Message, e.At)
+		if err != nil {
+			return err
+		}
+		if e.Id, err = res.LastInsertId(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *EventRepository) ForIssue(issueId int64) ([]*domain.IssueEvent, error) {
+	events := make([]*domain.IssueEvent, 0)
+	if err := r.db.Select(&events, querySelectIssueEvents, issueId); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *EventRepository) Since(afterId int64, limit int) ([]*domain.IssueEvent, error) {
+	events := make([]*domain.IssueEvent, 0)
+	if err := r.db.Select(&events, querySelectEventsSince, afterId, limit); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *EventRepository) InSprint(sprintId int64) ([]int64, error) {
+	ids := make([]int64, 0)
+	if err := r.db.Select(&ids, querySelectInSprint, strconv.FormatInt(sprintId, 10)); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *EventRepository) LastId() (int64, error) {
+	var id int64
+	if err := r.db.Get(&id, querySelectLastEventId); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+)
+
+func TestEventRepository_AppendOnly(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewEventRepository(conn)
+
+	e := &domain.IssueEvent{Type: domain.EventIssueFieldChanged, IssueId: 1, Field: "title", From: "a", To: "b", At: time.Now().UTC()}
+	if err := repo.Append(e); err != nil {
+		t.Fatal(err)
+	}
+	events, err := repo.ForIssue(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Field != "title" || events[0].To != "b" {
+		t.Fatalf("expected the appended event but got %+v", events)
+	}
+
+	if _, err := conn.Exec("UPDATE issue_events SET event_to='c' WHERE event_id=?", e.Id); err == nil {
+		t.Fatal("expected an update of an event to be refused")
+	}
+	if _, err := conn.Exec("DELETE FROM issue_events WHERE event_id=?", e.Id); err == nil {
+		t.Fatal("expected a delete of an event to be refused")
+	}
+}
+
+func TestEventRepository_Since(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewEventRepository(conn)
+
+	for i := int64(1); i <= 3; i++ {
+		if err := repo.Append(&domain.IssueEvent{Type: domain.EventIssueDeleted, IssueId: i, At: time.Now().UTC()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events, err := repo.Since(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Id != 2 {
+		t.Fatalf("expected only the second event but got %+v", events)
+	}
+	if events, _ := repo.Since(3, 10); len(events) != 0 {
+		t.Fatalf("expected no events after the last one but got %+v", events)
+	}
+}
+
+func TestEventRepository_BackfilledCreation(t *testing.T) {
+	conn, err := Connect(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	migrator, err := NewMigrator(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.To(15); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	title := "A \"quoted\"\\ title\non two lines\x01\x1f"
+	res, err := conn.Exec("INSERT INTO issues (issue_title, issue_description, issue_projectId, issue_ownerId, issue_priority, issue_status, issue_points, issue_createdAt, issue_updatedAt) VALUES (?, '', 1, 2, 'High', 'In Progress', 2.5, ?, ?)", title, now, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := res.LastInsertId()
+	repo := NewEventRepository(conn)
+	if err := repo.Append(&domain.IssueEvent{Type: domain.EventIssueFieldChanged, IssueId: id, Field: "points", From: "1", To: "2.5", At: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := repo.ForIssue(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Type != domain.EventIssueCreated {
+		t.Fatalf("expected the backfilled creation first but got %+v", events)
+	}
+	replayed, err := domain.ReplayIssue(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := NewIssueRepository(conn).GetById(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &domain.IssueConsistency{Stored: stored, Replayed: replayed}
+	c.Compare()
+	if len(c.Differences) != 0 || replayed.Id != id {
+		t.Fatalf("expected the replayed issue to match the stored one but got %v", c.Differences)
+	}
+}
+
+func TestEventRepository_InSprint(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewEventRepository(conn)
+	issues := NewIssueRepository(conn)
+
+	now := time.Now().UTC()
+	created := func(sprintId int64) *domain.Issue {
+		i := &domain.Issue{Title: "Bug", Priority: domain.PriorityLow, Status: "Open", SprintId: sprintId, Points: 1, CreatedAt: now, UpdatedAt: now}
+		snapshot, err := json.Marshal(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := issues.Create(i, &domain.IssueEvent{Type: domain.EventIssueCreated, To: string(snapshot), At: now}); err != nil {
+			t.Fatal(err)
+		}
+		return i
+	}
+	// deleted after being created in sprint 1, moved from 1 to 2, created in 11
+	deleted, moved, other := created(1), created(1), created(11)
+	if err := issues.Delete(deleted.Id, &domain.IssueEvent{Type: domain.EventIssueDeleted, IssueId: deleted.Id, At: now}); err != nil {
+		t.Fatal(err)
+	}
+	moved.SprintId = 2
+	if err := issues.Update(moved, nil, &domain.IssueEvent{Type: domain.EventIssueFieldChanged, IssueId: moved.Id, Field: "sprint", From: "1", To: "2", At: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.InSprint(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != deleted.Id || ids[1] != moved.Id {
+		t.Fatalf("expected the deleted and the moved issue in sprint 1 but got %v", ids)
+	}
+	if ids, _ := repo.InSprint(11); len(ids) != 1 || ids[0] != other.Id {
+		t.Fatalf("expected only issue %d in sprint 11 but got %v", other.Id, ids)
+	}
+}
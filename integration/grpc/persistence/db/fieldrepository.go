@@ -0,0 +1,115 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectField         = "SELECT * FROM field_definitions WHERE field_id=?"
+	querySelectProjectFields = "SELECT * FROM field_definitions WHERE field_projectId=? ORDER BY field_name"
+	queryInsertField         = "INSERT INTO field_definitions (field_projectId, field_name, field_type, field_options) VALUES (?, ?, ?, ?)"
+	queryDeleteField         = "DELETE FROM field_definitions WHERE field_id=?"
+	queryDeleteFieldValues   = "DELETE FROM issue_field_values WHERE value_fieldId=?"
+	querySelectIssueValues   = "SELECT v.*, d.field_name FROM issue_field_values v JOIN field_definitions d ON d.field_id=v.value_fieldId WHERE v.value_issueId=?"
+	queryUpsertFieldValue    = "INSERT INTO issue_field_values (value_issueId, value_fieldId, value_value) VALUES (?, ?, ?) ON CONFLICT(value_issueId, value_fieldId) DO UPDATE SET value_value=excluded.value_value"
+	queryDeleteFieldValue    = "DELETE FROM issue_field_values WHERE value_issueId=? AND value_fieldId=?"
+)
+
+type FieldRepository struct {
+	db *sqlx.DB
+}
+
+func NewFieldRepository(db *sqlx.DB) *FieldRepository {
+	return &FieldRepository{
+		db: db,
+	}
+}
+
+func (r *FieldRepository) GetById(id int64) (*domain.FieldDefinition, error) {
+	var d domain.FieldDefinition
+	err := r.db.Get(&d, querySelectField, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *FieldRepository) ForProject(projectId int64) ([]*domain.FieldDefinition, error) {
+	fields := make([]*domain.FieldDefinition, 0)
+	if err := r.db.Select(&fields, querySelectProjectFields, projectId); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func (r *FieldRepository) Create(d *domain.FieldDefinition) error {
+	res, err := r.db.Exec(queryInsertField, d.ProjectId, d.Name, d.Type, d.Options)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("field %s already exists: %w", d.Name, domain.ErrConflict)
+	}
+	if err != nil {
+		return err
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	d.Id = lastId
+	return nil
+}
+
+func (r *FieldRepository) Delete(id int64) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(queryDeleteFieldValues, id); err != nil {
+		return err
+	}
+	res, err := tx.Exec(queryDeleteField, id)
+	if err != nil {
+		return err
+	}
+	if err := expectOneRow(res); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *FieldRepository) Values(issueId int64) ([]*domain.FieldValue, error) {
+	values := make([]*domain.FieldValue, 0)
+	if err := r.db.Select(&values, querySelectIssueValues, issueId); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// SetValues stores the set values and removes the unset fields of the issue in one transaction.
+func (r *FieldRepository) SetValues(issueId int64, set []*domain.FieldValue, unset []int64) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, v := range set {
+		if _, err := tx.Exec(queryUpsertFieldValue, issueId, v.FieldId, v.Value); err != nil {
+			return err
+		}
+	}
+	for _, fieldId := range unset {
+		if _, err := tx.Exec(queryDeleteFieldValue, issueId, fieldId); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
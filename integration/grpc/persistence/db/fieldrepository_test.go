@@ -0,0 +1,41 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/pwera/ddd/domain"
+)
+
+func TestFieldRepository_SetValues(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewFieldRepository(conn)
+
+	severity := &domain.FieldDefinition{ProjectId: 1, Name: "severity", Type: domain.FieldText}
+	estimate := &domain.FieldDefinition{ProjectId: 1, Name: "estimate", Type: domain.FieldText}
+	for _, d := range []*domain.FieldDefinition{severity, estimate} {
+		if err := repo.Create(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	set := []*domain.FieldValue{{FieldId: severity.Id, Value: "low"}, {FieldId: estimate.Id, Value: "3"}}
+	if err := repo.SetValues(1, set, nil); err != nil {
+		t.Fatal(err)
+	}
+	set = []*domain.FieldValue{{FieldId: severity.Id, Value: "high"}}
+	if err := repo.SetValues(1, set, []int64{estimate.Id}); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := repo.Values(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || values[0].Name != "severity" || values[0].Value != "high" {
+		t.Fatalf("expected only severity=high but got %+v", values)
+	}
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectIssueHistory = "SELECT * FROM issue_history WHERE history_issueId=? ORDER BY history_id"
+	queryInsertHistory      = "INSERT INTO issue_history (history_issueId, history_userId, history_field, history_from, history_to, history_at) VALUES (?, ?, ?, ?, ?, ?)"
+)
+
+type HistoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewHistoryRepository(db *sqlx.DB) *HistoryRepository {
+	return &HistoryRepository{
+		db: db,
+	}
+}
+
+func (r *HistoryRepository) Add(e *domain.HistoryEntry) error {
+	res, err := r.db.Exec(queryInsertHistory, e.IssueId, e.UserId, e.Field, e.From, e.To, e.At)
+	if err != nil {
+		return err
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	e.Id = lastId
+	return nil
+}
+
+func (r *HistoryRepository) ForIssue(issueId int64) ([]*domain.HistoryEntry, error) {
+	entries := make([]*domain.HistoryEntry, 0)
+	err := r.db.Select(&entries, querySelectIssueHistory, issueId)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,206 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+)
+
+// issueColumns maps the query fields kept in the issues table to their columns.
+var issueColumns = map[string]string{
+	"id":          "issue_id",
+	"project":     "issue_projectId",
+	"priority":    "issue_priority",
+	"status":      "issue_status",
+	"title":       "issue_title",
+	"description": "issue_description",
+	"sprint":      "issue_sprintId",
+	"owner":       "issue_ownerId",
+	"created":     "issue_createdAt",
+	"updated":     "issue_updatedAt",
+}
+
+// issueMembers are the query fields kept in other tables, %s takes the placeholders.
+var issueMembers = map[string]string{
+	"assignee": "EXISTS (SELECT 1 FROM issue_assignees a WHERE a.issue_id=issues.issue_id AND a.user_id IN (%s))",
+	"watcher":  "EXISTS (SELECT 1 FROM issue_watchers w WHERE w.issue_id=issues.issue_id AND w.user_id IN (%s))",
+	"label":    "EXISTS (SELECT 1 FROM issue_labels il JOIN labels l ON l.label_id=il.label_id WHERE il.issue_id=issues.issue_id AND l.label_name IN (%s))",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type queryCompiler struct {
+	me   int64
+	args []interface{}
+}
+
+// compileQuery turns a parsed query into parameterised SQL, the values only
+// ever reach the database as arguments.
+func compileQuery(q *domain.Query, me int64) (where, orderBy string, args []interface{}, err error) {
+	c := &queryCompiler{me: me}
+	if q.Where != nil {
+		if where, err = c.expr(q.Where); err != nil {
+			return "", "", nil, err
+		}
+	}
+	return where, c.orderBy(q.OrderBy), c.args, nil
+}
+
+func (c *queryCompiler) expr(e domain.QueryExpr) (string, error) {
+	switch e := e.(type) {
+	case domain.QueryAnd:
+		return c.binary(e.Left, "AND", e.Right)
+	case domain.QueryOr:
+		return c.binary(e.Left, "OR", e.Right)
+	case domain.QueryNot:
+		inner, err := c.expr(e.Expr)
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + inner + ")", nil
+	case domain.QueryCondition:
+		return c.condition(e)
+	}
+	return "", fmt.Errorf("unknown query expression %T", e)
+}
+
+func (c *queryCompiler) binary(left domain.QueryExpr, op string, right domain.QueryExpr) (string, error) {
+	l, err := c.expr(left)
+	if err != nil {
+		return "", err
+	}
+	r, err := c.expr(right)
+	if err != nil {
+		return "", err
+	}
+	return "(" + l + " " + op + " " + r + ")", nil
+}
+
+func (c *queryCompiler) condition(cond domain.QueryCondition) (string, error) {
+	values, err := c.values(cond)
+	if err != nil {
+		return "", err
+	}
+	if member, ok := issueMembers[cond.Field]; ok {
+		sql := fmt.Sprintf(member, c.placeholders(values))
+		if cond.Op == domain.OpNotEqual || cond.Op == domain.OpNotIn {
+			sql = "NOT " + sql
+		}
+		return sql, nil
+	}
+
+	column := issueColumns[cond.Field]
+	if cond.Field == "priority" {
+		// compare the ranks so that "priority >= Medium" follows the order of the priorities
+		column = priorityRank()
+	}
+	if domain.QueryFields[cond.Field].Kind == domain.QueryDate {
+		return c.date(column, cond.Op, values[0].(time.Time)), nil
+	}
+	switch cond.Op {
+	case domain.OpIn:
+		return column + " IN (" + c.placeholders(values) + ")", nil
+	case domain.OpNotIn:
+		return column + " NOT IN (" + c.placeholders(values) + ")", nil
+	case domain.OpContains:
+		c.args = append(c.args, "%"+likeEscaper.Replace(values[0].(string))+"%")
+		return column + ` LIKE ? ESCAPE '\'`, nil
+	}
+	c.args = append(c.args, values[0])
+	return column + " " + cond.Op + " ?", nil
+}
+
+// date compares with whole days, "updated = 2026-01-02" matches the whole day.
+func (c *queryCompiler) date(column, op string, day time.Time) string {
+	next := day.AddDate(0, 0, 1)
+	switch op {
+	case domain.OpLess:
+		c.args = append(c.args, day)
+		return column + " < ?"
+	case domain.OpLessEqual:
+		c.args = append(c.args, next)
+		return column + " < ?"
+	case domain.OpGreater:
+		c.args = append(c.args, next)
+		return column + " >= ?"
+	case domain.OpGreaterEqual:
+		c.args = append(c.args, day)
+		return column + " >= ?"
+	case domain.OpNotEqual:
+		c.args = append(c.args, day, next)
+		return "(" + column + " < ? OR " + column + " >= ?)"
+	}
+	c.args = append(c.args, day, next)
+	return "(" + column + " >= ? AND " + column + " < ?)"
+}
+
+// values converts the values of the condition to the types of their column.
+func (c *queryCompiler) values(cond domain.QueryCondition) ([]interface{}, error) {
+	kind := domain.QueryFields[cond.Field].Kind
+	values := make([]interface{}, 0, len(cond.Values))
+	for _, v := range cond.Values {
+		switch {
+		case v.Me:
+			if c.me == 0 {
+				return nil, domain.NewValidationError("query: \"me\" needs a user")
+			}
+			values = append(values, c.me)
+		case kind == domain.QueryNumber || kind == domain.QueryUser:
+			n, err := strconv.ParseInt(v.Text, 10, 64)
+			if err != nil {
+				return nil, domain.NewValidationError(fmt.Sprintf("query: %q is not a number", v.Text))
+			}
+			values = append(values, n)
+		case kind == domain.QueryPriority:
+			p, err := domain.ParsePriority(v.Text)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, int(p))
+		case kind == domain.QueryDate:
+			day, err := time.Parse(domain.QueryDateLayout, v.Text)
+			if err != nil {
+				return nil, domain.NewValidationError(fmt.Sprintf("query: %q is not a date", v.Text))
+			}
+			values = append(values, day)
+		default:
+			values = append(values, v.Text)
+		}
+	}
+	return values, nil
+}
+
+func (c *queryCompiler) placeholders(values []interface{}) string {
+	c.args = append(c.args, values...)
+	return strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+}
+
+// orderBy always ends with issue_id so that pages of results are stable.
+func (c *queryCompiler) orderBy(orders []domain.QueryOrder) string {
+	var terms []string
+	for _, o := range orders {
+		term := issueColumns[o.Field]
+		if o.Field == "priority" {
+			term = priorityRank()
+		}
+		if o.Desc {
+			term += " DESC"
+		}
+		terms = append(terms, term)
+	}
+	return strings.Join(append(terms, "issue_id"), ", ")
+}
+
+// priorityRank turns the stored priority names back into the order of domain.Priority.
+func priorityRank() string {
+	var b strings.Builder
+	b.WriteString("CASE issue_priority")
+	for _, p := range domain.Priorities {
+		fmt.Fprintf(&b, " WHEN '%s' THEN %d", p, int(p))
+	}
+	b.WriteString(" END")
+	return b.String()
+}
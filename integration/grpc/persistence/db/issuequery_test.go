@@ -0,0 +1,87 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+)
+
+func TestIssueRepository_Query(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewIssueRepository(conn)
+	participants := NewParticipantRepository(conn)
+
+	now := time.Now().UTC()
+	for _, i := range []*domain.Issue{
+		{Title: "100% broken", ProjectId: 1, Priority: domain.PriorityLow, Status: domain.StatusOpen},
+		{Title: "slow", ProjectId: 1, Priority: domain.PriorityHigh, Status: domain.StatusOpen},
+		{Title: "typo", ProjectId: 2, Priority: domain.PriorityMedium, Status: domain.StatusOpen},
+	} {
+		i.CreatedAt, i.UpdatedAt = now, now
+		if err := repo.Create(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := participants.Assign(2, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		me    int64
+		want  []int64
+	}{
+		{"", 0, []int64{1, 2, 3}},
+		{"project = 1 ORDER BY priority DESC", 0, []int64{2, 1}},
+		{"priority in (High, Medium) AND assignee = me", 7, []int64{2}},
+		{"NOT assignee = 7", 0, []int64{1, 3}},
+		{"priority >= Medium ORDER BY priority", 0, []int64{3, 2}},
+		{"title ~ '100%'", 0, []int64{1}},
+		{"title ~ '%'", 0, []int64{1}},
+		{"created = " + now.Format(domain.QueryDateLayout), 0, []int64{1, 2, 3}},
+		{"updated < " + now.Format(domain.QueryDateLayout), 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := domain.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			issues, err := repo.Query(q, tt.me)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int64
+			for _, i := range issues {
+				got = append(got, i.Id)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected issues %v but got %v", tt.want, got)
+			}
+			for n := range got {
+				if got[n] != tt.want[n] {
+					t.Fatalf("expected issues %v but got %v", tt.want, got)
+				}
+			}
+		})
+	}
+
+	q, _ := domain.ParseQuery("assignee = me")
+	if _, err := repo.Query(q, 0); err == nil {
+		t.Fatal("expected me without a user to be rejected")
+	}
+
+	cutoffs := map[domain.Priority]time.Time{domain.PriorityHigh: now.Add(time.Second)}
+	late, err := repo.CreatedBefore(cutoffs, domain.DoneStatuses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(late) != 1 || late[0].Id != 2 {
+		t.Fatalf("expected only the High issue past its cutoff but got %+v", late)
+	}
+}
@@ -1,48 +1,46 @@
 package db
 
 import (
-	"log"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/pwera/ddd/domain"
 )
 
 const (
-	issuesDriver         = "sqlite3"
 	querySelectAllIssues = "SELECT * FROM issues"
 	querySelectIssue     = "SELECT * FROM issues WHERE issue_id=?"
-	queryInsertIssue     = "INSERT INTO issues (issue_title, issue_description, issue_projectId, issue_ownerId, issue_priority) VALUES (?, ?, ?, ?, ?)"
-	queryDeleteIssue     = "DELETE FROM issues WHERE issue_id=? LIMIT 1"
+	queryInsertIssue     = "INSERT INTO issues (issue_title, issue_description, issue_projectId, issue_ownerId, issue_priority, issue_status, issue_sprintId, issue_points, issue_createdAt, issue_updatedAt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	queryUpdateIssue     = "UPDATE issues SET issue_title=?, issue_description=?, issue_projectId=?, issue_ownerId=?, issue_priority=?, issue_status=?, issue_sprintId=?, issue_points=?, issue_updatedAt=? WHERE issue_id=?"
+	queryDeleteIssue     = "DELETE FROM issues WHERE issue_id=?"
 )
-const issueSchema = `CREATE TABLE IF NOT EXISTS issues(
-	issue_id integer primary key autoincrement,
-	issue_title text,
-	issue_description text,
-	issue_projectId integer,
-	issue_ownerId integer,
-	issue_priority text);`
+
+// queriesDeleteIssueRows remove what belongs to an issue being deleted, the
+// events and the notifications stay. The contents of the attachments are left
+// to the BlobStore.
+var queriesDeleteIssueRows = []string{
+	"DELETE FROM issue_links WHERE link_fromId=?1 OR link_toId=?1",
+	"DELETE FROM issue_labels WHERE issue_id=?1",
+	"DELETE FROM issue_field_values WHERE value_issueId=?1",
+	"DELETE FROM issue_assignees WHERE issue_id=?1",
+	"DELETE FROM issue_watchers WHERE issue_id=?1",
+	"DELETE FROM comment_revisions WHERE revision_commentId IN (SELECT comment_id FROM comments WHERE comment_issueId=?1)",
+	"DELETE FROM comments WHERE comment_issueId=?1",
+	"DELETE FROM issue_history WHERE history_issueId=?1",
+	"DELETE FROM attachments WHERE attachment_issueId=?1",
+}
 
 type IssueRepository struct {
 	db *sqlx.DB
 }
 
-func NewIssueRepository() *IssueRepository {
-	db, err := sqlx.Open(issuesDriver, ":memory:")
-
-	if err != nil {
-		log.Fatal("Failed to connect to database")
-	}
-	r := &IssueRepository{
+func NewIssueRepository(db *sqlx.DB) *IssueRepository {
+	return &IssueRepository{
 		db: db,
 	}
-	r.Init(issueSchema)
-	return r
-}
-
-func (r *IssueRepository) Init(schema string) error {
-	_, err := r.db.Exec(schema)
-	return err
 }
 
 func (r *IssueRepository) All() ([]*domain.Issue, error) {
@@ -62,25 +60,112 @@ func (r *IssueRepository) All() ([]*domain.Issue, error) {
 	return issues, nil
 }
 
+// Find builds the WHERE clause of querySelectAllIssues from the filter.
+func (r *IssueRepository) Find(f domain.IssueFilter) ([]*domain.Issue, error) {
+	var where []string
+	var args []interface{}
+	if f.ProjectId != 0 {
+		where = append(where, "issue_projectId=?")
+		args = append(args, f.ProjectId)
+	}
+	if f.SprintId != 0 {
+		where = append(where, "issue_sprintId=?")
+		args = append(args, f.SprintId)
+	}
+	for _, label := range f.Labels {
+		where = append(where, "EXISTS (SELECT 1 FROM issue_labels il JOIN labels l ON l.label_id=il.label_id WHERE il.issue_id=issues.issue_id AND l.label_name=?)")
+		args = append(args, label)
+	}
+	names := make([]string, 0, len(f.Fields))
+	for name := range f.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		where = append(where, "EXISTS (SELECT 1 FROM issue_field_values v JOIN field_definitions d ON d.field_id=v.value_fieldId WHERE v.value_issueId=issues.issue_id AND d.field_name=? AND v.value_value=?)")
+		args = append(args, name, f.Fields[name])
+	}
+
+	query := querySelectAllIssues
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	issues := make([]*domain.Issue, 0)
+	if err := r.db.Select(&issues, query+" ORDER BY issue_id", args...); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// Query compiles the query to the WHERE and ORDER BY clauses of querySelectAllIssues.
+func (r *IssueRepository) Query(q *domain.Query, me int64) ([]*domain.Issue, error) {
+	where, orderBy, args, err := compileQuery(q, me)
+	if err != nil {
+		return nil, err
+	}
+	query := querySelectAllIssues
+	if where != "" {
+		query += " WHERE " + where
+	}
+	issues := make([]*domain.Issue, 0)
+	if err := r.db.Select(&issues, query+" ORDER BY "+orderBy, args...); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+func (r *IssueRepository) CreatedBefore(cutoffs map[domain.Priority]time.Time, excluded []domain.Status) ([]*domain.Issue, error) {
+	if len(cutoffs) == 0 {
+		return make([]*domain.Issue, 0), nil
+	}
+	var late []string
+	var args []interface{}
+	for _, p := range domain.Priorities {
+		if cutoff, ok := cutoffs[p]; ok {
+			late = append(late, "(issue_priority=? AND issue_createdAt<?)")
+			args = append(args, p, cutoff)
+		}
+	}
+	query := querySelectAllIssues + " WHERE (" + strings.Join(late, " OR ") + ")"
+	for _, s := range excluded {
+		query += " AND issue_status<>?"
+		args = append(args, s)
+	}
+	issues := make([]*domain.Issue, 0)
+	if err := r.db.Select(&issues, query+" ORDER BY issue_id", args...); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
 func (r *IssueRepository) GetById(id int64) (*domain.Issue, error) {
 	stmt, err := r.db.Preparex(querySelectIssue)
 	if err != nil {
 		return nil, err
 	}
+	defer stmt.Close()
 	var u domain.Issue
 	err = stmt.Get(&u, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return &u, nil
 }
-func (r *IssueRepository) Create(i *domain.Issue) error {
-	stmt, err := r.db.Preparex(queryInsertIssue)
+
+// Create stores the issue and appends the events in one transaction, the events
+// get the id of the new issue.
+func (r *IssueRepository) Create(i *domain.Issue, events ...*domain.IssueEvent) error {
+	tx, err := r.db.Beginx()
 	if err != nil {
 		return err
 	}
-	res, err := stmt.Exec(i.Title, i.Description, i.ProjectId, i.OwnerId, i.Priority)
+	defer tx.Rollback()
+
+	res, err := tx.Exec(queryInsertIssue, i.Title, i.Description, i.ProjectId, i.OwnerId, i.Priority, i.Status, i.SprintId, i.Points, i.CreatedAt, i.UpdatedAt)
 	if err != nil {
 		return err
 	}
@@ -88,19 +173,85 @@ func (r *IssueRepository) Create(i *domain.Issue) error {
 	if err != nil {
 		return err
 	}
+	for _, e := range events {
+		e.IssueId = lastId
+	}
+	if err := appendEvents(tx, events); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 	i.Id = lastId
 	return nil
 }
 
-func (r *IssueRepository) Delete(id int64) error {
-	stmt, err := r.db.Prepare(queryDeleteIssue)
+// Update stores the issue, adds the history entries and appends the events in
+// one transaction.
+func (r *IssueRepository) Update(i *domain.Issue, history []*domain.HistoryEntry, events ...*domain.IssueEvent) error {
+	tx, err := r.db.Beginx()
 	if err != nil {
 		return err
 	}
-	_, err = stmt.Exec(id)
+	defer tx.Rollback()
+
+	res, err := tx.Exec(queryUpdateIssue, i.Title, i.Description, i.ProjectId, i.OwnerId, i.Priority, i.Status, i.SprintId, i.Points, i.UpdatedAt, i.Id)
 	if err != nil {
 		return err
 	}
+	if err := expectOneRow(res); err != nil {
+		return err
+	}
+	for _, e := range history {
+		res, err := tx.Exec(queryInsertHistory, e.IssueId, e.UserId, e.Field, e.From, e.To, e.At)
+		if err != nil {
+			return err
+		}
+		if e.Id, err = res.LastInsertId(); err != nil {
+			return err
+		}
+	}
+	if err := appendEvents(tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
+// Delete removes the issue with its links, labels, field values, participants,
+// comments, history and attachments and appends the events in one transaction.
+func (r *IssueRepository) Delete(id int64, events ...*domain.IssueEvent) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(queryDeleteIssue, id)
+	if err != nil {
+		return err
+	}
+	if err := expectOneRow(res); err != nil {
+		return err
+	}
+	for _, query := range queriesDeleteIssueRows {
+		if _, err := tx.Exec(query, id); err != nil {
+			return err
+		}
+	}
+	if err := appendEvents(tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// expectOneRow turns an update or delete that matched nothing into domain.ErrNotFound.
+func expectOneRow(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
 	return nil
 }
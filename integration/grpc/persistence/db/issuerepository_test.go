@@ -0,0 +1,194 @@
+package db
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+func TestIssueRepository_CRUD(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewIssueRepository(conn)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	i := &domain.Issue{Title: "Bug", ProjectId: 1, OwnerId: 2, Priority: domain.PriorityHigh, Status: "open", Points: 3, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(i); err != nil {
+		t.Fatal(err)
+	}
+	i.Title = "Renamed"
+	i.Status = "done"
+	if err := repo.Update(i, nil); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := repo.GetById(i.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Title != "Renamed" || stored.Status != "done" || stored.Priority != domain.PriorityHigh || stored.Points != 3 || !stored.CreatedAt.Equal(now) {
+		t.Fatalf("expected %+v but got %+v", i, stored)
+	}
+
+	if _, err := repo.GetById(99); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing issue but got %v", err)
+	}
+	if err := repo.Update(&domain.Issue{Id: 99, Title: "Missing", Priority: domain.PriorityLow}, nil); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound updating a missing issue but got %v", err)
+	}
+	if err := repo.Delete(i.Id); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(i.Id); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound deleting the issue twice but got %v", err)
+	}
+}
+
+func TestIssueRepository_LegacyPriorities(t *testing.T) {
+	legacy := func(priorities ...interface{}) (*sqlx.DB, error) {
+		conn, err := Connect(DefaultDSN)
+		if err != nil {
+			t.Fatalf("error opening database: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		migrator, err := NewMigrator(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := migrator.To(14); err != nil {
+			t.Fatal(err)
+		}
+		now := time.Now().UTC()
+		for _, priority := range priorities {
+			_, err := conn.Exec("INSERT INTO issues (issue_priority, issue_createdAt, issue_updatedAt) VALUES (?, ?, ?)", priority, now, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return conn, migrator.Up()
+	}
+
+	conn, err := legacy(nil, " high", "medium", "Low")
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err := NewIssueRepository(conn).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.Priority{0, domain.PriorityHigh, domain.PriorityMedium, domain.PriorityLow}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues but got %d", len(want), len(issues))
+	}
+	for n, i := range issues {
+		if i.Priority != want[n] {
+			t.Fatalf("expected issue %d to be %s but got %s", i.Id, want[n], i.Priority)
+		}
+	}
+
+	if _, err := legacy("Low", "urgent"); err == nil || !strings.Contains(err.Error(), "unknown_issue_priority") {
+		t.Fatalf("expected an unknown priority to fail the migration but got %v", err)
+	}
+}
+
+func TestIssueRepository_AppendsEvents(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewIssueRepository(conn)
+
+	now := time.Now().UTC()
+	i := &domain.Issue{Title: "Bug", Priority: domain.PriorityLow, Status: "Open", CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(i, &domain.IssueEvent{Type: domain.EventIssueCreated, At: now}); err != nil {
+		t.Fatal(err)
+	}
+	i.Status = "Closed"
+	entry := &domain.HistoryEntry{IssueId: i.Id, Field: "status", From: "Open", To: "Closed", At: now}
+	if err := repo.Update(i, []*domain.HistoryEntry{entry}, &domain.IssueEvent{Type: domain.EventIssueTransitioned, IssueId: i.Id, At: now}); err != nil {
+		t.Fatal(err)
+	}
+	history, err := NewHistoryRepository(conn).ForIssue(i.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || entry.Id == 0 {
+		t.Fatalf("expected the history entry of the update but got %+v", history)
+	}
+	if err := repo.Delete(i.Id, &domain.IssueEvent{Type: domain.EventIssueDeleted, IssueId: i.Id, At: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(i.Id, &domain.IssueEvent{Type: domain.EventIssueDeleted, IssueId: i.Id, At: now}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound deleting the issue twice but got %v", err)
+	}
+
+	events, err := NewEventRepository(conn).ForIssue(i.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.EventType{domain.EventIssueCreated, domain.EventIssueTransitioned, domain.EventIssueDeleted}
+	if len(events) != len(want) {
+		t.Fatalf("expected an event for each stored change but got %+v", events)
+	}
+	for n, e := range events {
+		if e.Type != want[n] {
+			t.Fatalf("expected event %d to be %s but got %s", n, want[n], e.Type)
+		}
+	}
+}
+
+func TestIssueRepository_DeleteCascades(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewIssueRepository(conn)
+
+	now := time.Now().UTC()
+	i := &domain.Issue{Title: "Bug", Priority: domain.PriorityLow, Status: "Open", CreatedAt: now, UpdatedAt: now}
+	other := &domain.Issue{Title: "Other", Priority: domain.PriorityLow, Status: "Open", CreatedAt: now, UpdatedAt: now}
+	for _, issue := range []*domain.Issue{i, other} {
+		if err := repo.Create(issue); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := NewLinkRepository(conn).Create(&domain.IssueLink{FromId: other.Id, ToId: i.Id, Type: domain.LinkBlocks}); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewLabelRepository(conn).Attach(i.Id, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewParticipantRepository(conn).Assign(i.Id, 1); err != nil {
+		t.Fatal(err)
+	}
+	comments := NewCommentRepository(conn)
+	c := &domain.Comment{IssueId: i.Id, AuthorId: 1, Body: "first", CreatedAt: now, UpdatedAt: now}
+	if err := comments.Create(c); err != nil {
+		t.Fatal(err)
+	}
+	c.Body = "second"
+	if err := comments.Update(c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Delete(i.Id); err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range []string{"issue_links", "issue_labels", "issue_assignees", "comments", "comment_revisions"} {
+		var rows int
+		if err := conn.Get(&rows, "SELECT COUNT(*) FROM "+table); err != nil {
+			t.Fatal(err)
+		}
+		if rows != 0 {
+			t.Fatalf("expected %s to lose the rows of the deleted issue but %d are left", table, rows)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectLabel         = "SELECT * FROM labels WHERE label_id=?"
+	querySelectProjectLabels = "SELECT * FROM labels WHERE label_projectId=? ORDER BY label_name"
+	querySelectIssueLabels   = "SELECT l.* FROM labels l JOIN issue_labels il ON il.label_id=l.label_id WHERE il.issue_id=? ORDER BY l.label_name"
+	queryInsertLabel         = "INSERT INTO labels (label_projectId, label_name, label_color) VALUES (?, ?, ?)"
+	queryDeleteLabel         = "DELETE FROM labels WHERE label_id=?"
+	queryDeleteLabelIssues   = "DELETE FROM issue_labels WHERE label_id=?"
+	queryAttachLabel         = "INSERT OR IGNORE INTO issue_labels (issue_id, label_id) VALUES (?, ?)"
+	queryDetachLabel         = "DELETE FROM issue_labels WHERE issue_id=? AND label_id=?"
+)
+
+type LabelRepository struct {
+	db *sqlx.DB
+}
+
+func NewLabelRepository(db *sqlx.DB) *LabelRepository {
+	return &LabelRepository{
+		db: db,
+	}
+}
+
+func (r *LabelRepository) GetById(id int64) (*domain.Label, error) {
+	var l domain.Label
+	err := r.db.Get(&l, querySelectLabel, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *LabelRepository) ForProject(projectId int64) ([]*domain.Label, error) {
+	labels := make([]*domain.Label, 0)
+	if err := r.db.Select(&labels, querySelectProjectLabels, projectId); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (r *LabelRepository) ForIssue(issueId int64) ([]*domain.Label, error) {
+	labels := make([]*domain.Label, 0)
+	if err := r.db.Select(&labels, querySelectIssueLabels, issueId); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (r *LabelRepository) Create(l *domain.Label) error {
+	res, err := r.db.Exec(queryInsertLabel, l.ProjectId, l.Name, l.Color)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("label %s already exists: %w", l.Name, domain.ErrConflict)
+	}
+	if err != nil {
+		return err
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	l.Id = lastId
+	return nil
+}
+
+func (r *LabelRepository) Delete(id int64) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(queryDeleteLabelIssues, id); err != nil {
+		return err
+	}
+	res, err := tx.Exec(queryDeleteLabel, id)
+	if err != nil {
+		return err
+	}
+	if err := expectOneRow(res); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *LabelRepository) Attach(issueId, labelId int64) error {
+	_, err := r.db.Exec(queryAttachLabel, issueId, labelId)
+	return err
+}
+
+func (r *LabelRepository) Detach(issueId, labelId int64) error {
+	res, err := r.db.Exec(queryDetachLabel, issueId, labelId)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectLink         = "SELECT * FROM issue_links WHERE link_id=?"
+	querySelectIssueLinks   = "SELECT * FROM issue_links WHERE link_fromId=? OR link_toId=? ORDER BY link_id"
+	querySelectProjectLinks = "SELECT * FROM issue_links WHERE link_fromId IN (SELECT issue_id FROM issues WHERE issue_projectId=?) OR link_toId IN (SELECT issue_id FROM issues WHERE issue_projectId=?) ORDER BY link_id"
+	queryInsertLink         = "INSERT INTO issue_links (link_fromId, link_toId, link_type) VALUES (?, ?, ?)"
+	queryDeleteLink         = "DELETE FROM issue_links WHERE link_id=?"
+	// UNION drops the ids already seen, which also stops at cycles
+	querySelectUpstream = `WITH RECURSIVE upstream(id) AS (
+	SELECT link_fromId FROM issue_links WHERE link_toId=? AND link_type=?
+	UNION
+	SELECT l.link_fromId FROM issue_links l JOIN upstream u ON l.link_toId=u.id WHERE l.link_type=?)
+SELECT id FROM upstream ORDER BY id`
+)
+
+type LinkRepository struct {
+	db *sqlx.DB
+}
+
+func NewLinkRepository(db *sqlx.DB) *LinkRepository {
+	return &LinkRepository{
+		db: db,
+	}
+}
+
+func (r *LinkRepository) GetById(id int64) (*domain.IssueLink, error) {
+	var l domain.IssueLink
+	err := r.db.Get(&l, querySelectLink, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *LinkRepository) ForIssue(issueId int64) ([]*domain.IssueLink, error) {
+	links := make([]*domain.IssueLink, 0)
+	if err := r.db.Select(&links, querySelectIssueLinks, issueId, issueId); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// ForProject returns the links with at least one end in the project.
+func (r *LinkRepository) ForProject(projectId int64) ([]*domain.IssueLink, error) {
+	links := make([]*domain.IssueLink, 0)
+	if err := r.db.Select(&links, querySelectProjectLinks, projectId, projectId); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// Create looks for a cycle in the transaction of the insert.
+func (r *LinkRepository) Create(l *domain.IssueLink) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if l.Type.Acyclic() {
+		upstream := make([]int64, 0)
+		if err := tx.Select(&upstream, querySelectUpstream, l.FromId, l.Type, l.Type); err != nil {
+			return err
+		}
+		for _, id := range upstream {
+			if id == l.ToId {
+				return domain.NewValidationError(fmt.Sprintf("issue %d already %s issue %d, the link would make a cycle", l.ToId, l.Type, l.FromId))
+			}
+		}
+	}
+	res, err := tx.Exec(queryInsertLink, l.FromId, l.ToId, l.Type)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("issue %d already %s issue %d: %w", l.FromId, l.Type, l.ToId, domain.ErrConflict)
+	}
+	if err != nil {
+		return err
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	l.Id = lastId
+	return nil
+}
+
+func (r *LinkRepository) Delete(id int64) error {
+	res, err := r.db.Exec(queryDeleteLink, id)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func (r *LinkRepository) Upstream(issueId int64, t domain.LinkType) ([]int64, error) {
+	ids := make([]int64, 0)
+	if err := r.db.Select(&ids, querySelectUpstream, issueId, t, t); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pwera/ddd/domain"
+)
+
+func TestLinkRepository_Upstream(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewLinkRepository(conn)
+
+	// 1 blocks 2 blocks 3, 4 blocks 3, 5 only relates to 3
+	for _, l := range []*domain.IssueLink{
+		{FromId: 1, ToId: 2, Type: domain.LinkBlocks},
+		{FromId: 2, ToId: 3, Type: domain.LinkBlocks},
+		{FromId: 4, ToId: 3, Type: domain.LinkBlocks},
+		{FromId: 3, ToId: 5, Type: domain.LinkRelatesTo},
+	} {
+		if err := repo.Create(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	upstream, err := repo.Upstream(3, domain.LinkBlocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(upstream) != 3 || upstream[0] != 1 || upstream[1] != 2 || upstream[2] != 4 {
+		t.Fatalf("expected issues 1, 2 and 4 to block 3 but got %v", upstream)
+	}
+
+	var validation *domain.ValidationError
+	if err := repo.Create(&domain.IssueLink{FromId: 3, ToId: 1, Type: domain.LinkBlocks}); !errors.As(err, &validation) {
+		t.Fatalf("expected a validation error for a link closing a cycle but got %v", err)
+	}
+
+	// a cycle stored before they were refused must not keep the query going
+	if _, err := conn.Exec(queryInsertLink, 3, 1, domain.LinkBlocks); err != nil {
+		t.Fatal(err)
+	}
+	if upstream, err := repo.Upstream(1, domain.LinkBlocks); err != nil || len(upstream) != 4 {
+		t.Fatalf("expected the four issues of the cycle but got %v, %v", upstream, err)
+	}
+	if err := repo.Create(&domain.IssueLink{FromId: 1, ToId: 2, Type: domain.LinkBlocks}); err == nil {
+		t.Fatal("expected a duplicate link to be refused")
+	}
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+const (
+	querySchemaVersionTable = `CREATE TABLE IF NOT EXISTS schema_version(
+	version integer primary key,
+	name text,
+	applied_at timestamp default current_timestamp);`
+	querySchemaVersion       = "SELECT COALESCE(MAX(version), 0) FROM schema_version"
+	queryInsertSchemaVersion = "INSERT INTO schema_version (version, name) VALUES (?, ?)"
+	queryDeleteSchemaVersion = "DELETE FROM schema_version WHERE version=?"
+)
+
+// Migration is a pair of migrations/NNNN_name.up.sql and NNNN_name.down.sql files.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrator applies the embedded migrations and keeps track of them
+// in the schema_version table.
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *sqlx.DB) (*Migrator, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(querySchemaVersionTable); err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func loadMigrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		parts := migrationName.FindStringSubmatch(e.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", e.Name())
+		}
+		version, _ := strconv.Atoi(parts[1])
+		body, err := migrationFiles.ReadFile(path.Join("migrations", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = m
+		} else if m.Name != parts[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, parts[2])
+		}
+		if parts[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Version returns the version of the last applied migration, 0 for an empty database.
+func (m *Migrator) Version() (int, error) {
+	var version int
+	err := m.db.Get(&version, querySchemaVersion)
+	return version, err
+}
+
+// Latest returns the version of the last embedded migration.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up() error {
+	return m.To(m.Latest())
+}
+
+// To migrates up or down until version is the current one.
+// Every migration runs in its own transaction.
+func (m *Migrator) To(version int) error {
+	current, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	if version >= current {
+		for _, mig := range m.migrations {
+			if mig.Version > current && mig.Version <= version {
+				if err := m.apply(mig.Up, queryInsertSchemaVersion, mig.Version, mig.Name); err != nil {
+					return fmt.Errorf("migration %d_%s up: %w", mig.Version, mig.Name, err)
+				}
+			}
+		}
+		return nil
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if mig.Version <= current && mig.Version > version {
+			if err := m.apply(mig.Down, queryDeleteSchemaVersion, mig.Version); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", mig.Version, mig.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) apply(script string, bookkeeping string, args ...interface{}) error {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(script); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(bookkeeping, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMigrator_UpAndDown(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+
+	migrator, err := NewMigrator(conn)
+	if err != nil {
+		t.Fatalf("error loading migrations: %v", err)
+	}
+	version, err := migrator.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != migrator.Latest() {
+		t.Fatalf("expected version %d after Open but got %d", migrator.Latest(), version)
+	}
+
+	if err := migrator.To(0); err != nil {
+		t.Fatalf("error migrating down: %v", err)
+	}
+	var tables int
+	if err := conn.Get(&tables, "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name NOT IN ('schema_version', 'sqlite_sequence')"); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Fatalf("expected no table left but got %d", tables)
+	}
+
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("error migrating up again: %v", err)
+	}
+	if version, _ := migrator.Version(); version != migrator.Latest() {
+		t.Fatalf("expected version %d but got %d", migrator.Latest(), version)
+	}
+}
+
+func TestConnect_LeavesTheSchemaAlone(t *testing.T) {
+	conn, err := Connect(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+
+	migrator, err := NewMigrator(conn)
+	if err != nil {
+		t.Fatalf("error loading migrations: %v", err)
+	}
+	if version, err := migrator.Version(); err != nil || version != 0 {
+		t.Fatalf("expected an empty schema after Connect but got version %d, %v", version, err)
+	}
+	if err := migrator.To(2); err != nil {
+		t.Fatal(err)
+	}
+	var tables int
+	if err := conn.Get(&tables, "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='users'"); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Fatal("expected the users table of migration 3 to be left out")
+	}
+}
+
+func TestMigrator_RemovesOrphanedAttachments(t *testing.T) {
+	conn, err := Connect(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+
+	migrator, err := NewMigrator(conn)
+	if err != nil {
+		t.Fatalf("error loading migrations: %v", err)
+	}
+	if err := migrator.To(16); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	res, err := conn.Exec("INSERT INTO issues (issue_title, issue_priority, issue_createdAt, issue_updatedAt) VALUES ('Kept', 'Low', ?, ?)", now, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, _ := res.LastInsertId()
+	for _, issueId := range []int64{kept, kept + 1} {
+		_, err := conn.Exec("INSERT INTO attachments (attachment_issueId, attachment_name, attachment_contentType, attachment_size, attachment_digest, attachment_createdAt) VALUES (?, 'notes.txt', 'text/plain', 5, 'digest', ?)", issueId, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	var issueIds []int64
+	if err := conn.Select(&issueIds, "SELECT attachment_issueId FROM attachments"); err != nil {
+		t.Fatal(err)
+	}
+	if len(issueIds) != 1 || issueIds[0] != kept {
+		t.Fatalf("expected only the attachment of issue %d to be left but got the ones of %v", kept, issueIds)
+	}
+}
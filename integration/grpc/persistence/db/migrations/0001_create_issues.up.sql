@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS issues(
+	issue_id integer primary key autoincrement,
+	issue_title text,
+	issue_description text,
+	issue_projectId integer,
+	issue_ownerId integer,
+	issue_priority text);
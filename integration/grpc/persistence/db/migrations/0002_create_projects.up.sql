@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS projects(
+	project_id integer primary key autoincrement,
+	project_name text,
+	project_ownerId integer,
+	project_description text);
@@ -0,0 +1,4 @@
+CREATE TABLE users(
+	user_id integer primary key autoincrement,
+	user_name text not null,
+	user_email text not null unique collate nocase);
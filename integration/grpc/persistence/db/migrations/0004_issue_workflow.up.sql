@@ -0,0 +1,15 @@
+ALTER TABLE issues ADD COLUMN issue_status text NOT NULL DEFAULT 'Open';
+
+CREATE TABLE workflows(
+	workflow_projectId integer primary key,
+	workflow_definition text not null);
+
+CREATE TABLE issue_history(
+	history_id integer primary key autoincrement,
+	history_issueId integer not null,
+	history_userId integer,
+	history_field text not null,
+	history_from text,
+	history_to text,
+	history_at timestamp not null);
+CREATE INDEX issue_history_issue ON issue_history(history_issueId);
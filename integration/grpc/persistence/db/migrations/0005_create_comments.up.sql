@@ -0,0 +1,17 @@
+CREATE TABLE comments(
+	comment_id integer primary key autoincrement,
+	comment_issueId integer not null,
+	comment_parentId integer not null default 0,
+	comment_authorId integer not null,
+	comment_body text not null,
+	comment_deleted boolean not null default 0,
+	comment_createdAt timestamp not null,
+	comment_updatedAt timestamp not null);
+CREATE INDEX comments_issue ON comments(comment_issueId);
+
+CREATE TABLE comment_revisions(
+	revision_id integer primary key autoincrement,
+	revision_commentId integer not null,
+	revision_body text not null,
+	revision_editedAt timestamp not null);
+CREATE INDEX comment_revisions_comment ON comment_revisions(revision_commentId);
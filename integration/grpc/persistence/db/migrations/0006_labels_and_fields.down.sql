@@ -0,0 +1,4 @@
+DROP TABLE issue_field_values;
+DROP TABLE field_definitions;
+DROP TABLE issue_labels;
+DROP TABLE labels;
@@ -0,0 +1,26 @@
+CREATE TABLE labels(
+	label_id integer primary key autoincrement,
+	label_projectId integer not null,
+	label_name text not null,
+	label_color text not null,
+	UNIQUE(label_projectId, label_name));
+
+CREATE TABLE issue_labels(
+	issue_id integer not null,
+	label_id integer not null,
+	PRIMARY KEY(issue_id, label_id));
+
+CREATE TABLE field_definitions(
+	field_id integer primary key autoincrement,
+	field_projectId integer not null,
+	field_name text not null,
+	field_type text not null,
+	field_options text not null default '[]',
+	UNIQUE(field_projectId, field_name));
+
+CREATE TABLE issue_field_values(
+	value_issueId integer not null,
+	value_fieldId integer not null,
+	value_value text not null,
+	PRIMARY KEY(value_issueId, value_fieldId));
+CREATE INDEX issue_field_values_field ON issue_field_values(value_fieldId, value_value);
@@ -0,0 +1,3 @@
+DROP TABLE notifications;
+DROP TABLE issue_watchers;
+DROP TABLE issue_assignees;
@@ -0,0 +1,19 @@
+CREATE TABLE issue_assignees(
+	issue_id integer not null,
+	user_id integer not null,
+	PRIMARY KEY(issue_id, user_id));
+
+CREATE TABLE issue_watchers(
+	issue_id integer not null,
+	user_id integer not null,
+	PRIMARY KEY(issue_id, user_id));
+
+CREATE TABLE notifications(
+	notification_id integer primary key autoincrement,
+	notification_userId integer not null,
+	notification_issueId integer not null,
+	notification_event text not null,
+	notification_message text not null,
+	notification_read boolean not null default 0,
+	notification_createdAt timestamp not null);
+CREATE INDEX notifications_user ON notifications(notification_userId, notification_read);
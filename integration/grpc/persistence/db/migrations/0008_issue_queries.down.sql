@@ -0,0 +1,14 @@
+DROP TABLE saved_queries;
+
+-- SQLite 3.31 can't drop a column, the table is copied instead
+CREATE TABLE issues_down(
+	issue_id integer primary key autoincrement,
+	issue_title text,
+	issue_description text,
+	issue_projectId integer,
+	issue_ownerId integer,
+	issue_priority text,
+	issue_status text NOT NULL DEFAULT 'Open');
+INSERT INTO issues_down SELECT issue_id, issue_title, issue_description, issue_projectId, issue_ownerId, issue_priority, issue_status FROM issues;
+DROP TABLE issues;
+ALTER TABLE issues_down RENAME TO issues;
@@ -0,0 +1,12 @@
+-- SQLite can't add a column with a CURRENT_TIMESTAMP default, the existing rows are stamped after
+ALTER TABLE issues ADD COLUMN issue_createdAt timestamp NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE issues ADD COLUMN issue_updatedAt timestamp NOT NULL DEFAULT '1970-01-01 00:00:00';
+UPDATE issues SET issue_createdAt=CURRENT_TIMESTAMP, issue_updatedAt=CURRENT_TIMESTAMP;
+CREATE INDEX issues_updated ON issues(issue_updatedAt);
+
+CREATE TABLE saved_queries(
+	query_id integer primary key autoincrement,
+	query_userId integer not null,
+	query_name text not null,
+	query_text text not null,
+	UNIQUE(query_userId, query_name));
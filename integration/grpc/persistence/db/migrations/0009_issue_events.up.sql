@@ -0,0 +1,21 @@
+CREATE TABLE issue_events(
+	event_id integer primary key autoincrement,
+	event_type text not null,
+	event_issueId integer not null,
+	event_actorId integer not null default 0,
+	event_field text not null default '',
+	event_from text not null default '',
+	event_to text not null default '',
+	event_message text not null default '',
+	event_at timestamp not null);
+CREATE INDEX issue_events_issue ON issue_events(event_issueId);
+
+-- the events are an append-only log
+CREATE TRIGGER issue_events_no_update BEFORE UPDATE ON issue_events
+BEGIN
+	SELECT RAISE(ABORT, 'issue_events is append-only');
+END;
+CREATE TRIGGER issue_events_no_delete BEFORE DELETE ON issue_events
+BEGIN
+	SELECT RAISE(ABORT, 'issue_events is append-only');
+END;
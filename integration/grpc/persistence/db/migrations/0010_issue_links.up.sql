@@ -0,0 +1,7 @@
+CREATE TABLE issue_links(
+	link_id integer primary key autoincrement,
+	link_fromId integer not null,
+	link_toId integer not null,
+	link_type text not null,
+	UNIQUE(link_fromId, link_toId, link_type));
+CREATE INDEX issue_links_to ON issue_links(link_toId, link_type);
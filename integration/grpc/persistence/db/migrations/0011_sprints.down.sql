@@ -0,0 +1,17 @@
+DROP TABLE sprints;
+
+-- SQLite 3.31 can't drop a column, the table is copied instead
+CREATE TABLE issues_down(
+	issue_id integer primary key autoincrement,
+	issue_title text,
+	issue_description text,
+	issue_projectId integer,
+	issue_ownerId integer,
+	issue_priority text,
+	issue_status text NOT NULL DEFAULT 'Open',
+	issue_createdAt timestamp NOT NULL DEFAULT '1970-01-01 00:00:00',
+	issue_updatedAt timestamp NOT NULL DEFAULT '1970-01-01 00:00:00');
+INSERT INTO issues_down SELECT issue_id, issue_title, issue_description, issue_projectId, issue_ownerId, issue_priority, issue_status, issue_createdAt, issue_updatedAt FROM issues;
+DROP TABLE issues;
+ALTER TABLE issues_down RENAME TO issues;
+CREATE INDEX issues_updated ON issues(issue_updatedAt);
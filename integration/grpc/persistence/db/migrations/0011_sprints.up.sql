@@ -0,0 +1,12 @@
+CREATE TABLE sprints(
+	sprint_id integer primary key autoincrement,
+	sprint_projectId integer not null,
+	sprint_name text not null,
+	sprint_kind text not null default 'sprint',
+	sprint_start timestamp not null,
+	sprint_end timestamp not null,
+	UNIQUE(sprint_projectId, sprint_name));
+
+ALTER TABLE issues ADD COLUMN issue_sprintId integer NOT NULL DEFAULT 0;
+ALTER TABLE issues ADD COLUMN issue_points real NOT NULL DEFAULT 0;
+CREATE INDEX issues_sprint ON issues(issue_sprintId);
@@ -0,0 +1,10 @@
+CREATE TABLE attachments(
+	attachment_id integer primary key autoincrement,
+	attachment_issueId integer not null,
+	attachment_name text not null,
+	attachment_contentType text not null,
+	attachment_size integer not null,
+	attachment_digest text not null,
+	attachment_createdAt timestamp not null);
+CREATE INDEX attachments_issue ON attachments(attachment_issueId);
+CREATE INDEX attachments_digest ON attachments(attachment_digest);
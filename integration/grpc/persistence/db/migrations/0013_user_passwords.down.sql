@@ -0,0 +1,8 @@
+-- SQLite 3.31 can't drop a column, the table is copied instead
+CREATE TABLE users_down(
+	user_id integer primary key autoincrement,
+	user_name text not null,
+	user_email text not null unique collate nocase);
+INSERT INTO users_down SELECT user_id, user_name, user_email FROM users;
+DROP TABLE users;
+ALTER TABLE users_down RENAME TO users;
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN user_passwordHash text not null default '';
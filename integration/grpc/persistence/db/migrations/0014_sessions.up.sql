@@ -0,0 +1,12 @@
+CREATE TABLE refresh_tokens(
+	refresh_hash text primary key,
+	refresh_userId integer not null,
+	refresh_family text not null,
+	refresh_expiresAt timestamp not null,
+	refresh_usedAt timestamp,
+	refresh_revoked integer not null default 0);
+CREATE INDEX refresh_tokens_family ON refresh_tokens(refresh_family);
+CREATE TABLE revoked_tokens(
+	revoked_id text primary key,
+	revoked_expiresAt timestamp not null);
+CREATE INDEX revoked_tokens_expires ON revoked_tokens(revoked_expiresAt);
@@ -0,0 +1,2 @@
+-- the values replaced on the way up are not kept
+SELECT 1;
@@ -0,0 +1,19 @@
+-- the columns of migration 1 are nullable and the priority was free text,
+-- NULL stays no priority and a text that names no priority fails the
+-- migration, it has to be fixed by hand
+UPDATE issues SET
+	issue_title=COALESCE(issue_title, ''),
+	issue_description=COALESCE(issue_description, ''),
+	issue_projectId=COALESCE(issue_projectId, 0),
+	issue_ownerId=COALESCE(issue_ownerId, 0);
+UPDATE issues SET issue_priority=CASE lower(trim(issue_priority))
+	WHEN 'low' THEN 'Low'
+	WHEN 'medium' THEN 'Medium'
+	WHEN 'high' THEN 'High'
+	ELSE issue_priority END
+WHERE issue_priority IS NOT NULL;
+CREATE TEMP TABLE legacy_priorities(
+	issue_id integer,
+	issue_priority text CONSTRAINT unknown_issue_priority CHECK (issue_priority IS NULL OR issue_priority IN ('Low', 'Medium', 'High')));
+INSERT INTO legacy_priorities SELECT issue_id, issue_priority FROM issues;
+DROP TABLE legacy_priorities;
@@ -0,0 +1,2 @@
+-- issue_events is append-only, the backfilled events stay
+SELECT 1;
@@ -0,0 +1,26 @@
+-- issues from before the event log have no creation event, so they can't be
+-- replayed, the snapshot is the issue before the changes already logged
+INSERT INTO issue_events (event_type, event_issueId, event_to, event_message, event_at)
+SELECT 'issue.created', issue_id,
+	json_object(
+		'Title', title,
+		'Description', description,
+		'ProjectId', CAST(project AS INTEGER),
+		'OwnerId', CAST(owner AS INTEGER),
+		'Priority', priority,
+		'Status', status,
+		'SprintId', CAST(sprint AS INTEGER),
+		'Points', CAST(points AS REAL)),
+	'issue "' || title || '" was created',
+	issue_createdAt
+FROM (SELECT issue_id, issue_createdAt,
+	COALESCE((SELECT event_from FROM issue_events WHERE event_issueId=issue_id AND event_field='title' ORDER BY event_id LIMIT 1), issue_title) AS title,
+	COALESCE((SELECT event_from FROM issue_events WHERE event_issueId=issue_id AND event_field='description' ORDER BY event_id LIMIT 1), issue_description) AS description,
+	COALESCE((SELECT event_from FROM issue_events WHERE event_issueId=issue_id AND event_field='project' ORDER BY event_id LIMIT 1), issue_projectId) AS project,
+	COALESCE((SELECT event_from FROM issue_events WHERE event_issueId=issue_id AND event_field='owner' ORDER BY event_id LIMIT 1), issue_ownerId) AS owner,
+	COALESCE((SELECT event_from FROM issue_events WHERE event_issueId=issue_id AND event_field='priority' ORDER BY event_id LIMIT 1), issue_priority) AS priority,
+	COALESCE((SELECT event_from FROM issue_events WHERE event_issueId=issue_id AND event_field='status' ORDER BY event_id LIMIT 1), issue_status) AS status,
+	COALESCE((SELECT event_from FROM issue_events WHERE event_issueId=issue_id AND event_field='sprint' ORDER BY event_id LIMIT 1), issue_sprintId) AS sprint,
+	COALESCE((SELECT event_from FROM issue_events WHERE event_issueId=issue_id AND event_field='points' ORDER BY event_id LIMIT 1), issue_points) AS points
+	FROM issues
+	WHERE NOT EXISTS (SELECT 1 FROM issue_events WHERE event_issueId=issue_id AND event_type='issue.created'));
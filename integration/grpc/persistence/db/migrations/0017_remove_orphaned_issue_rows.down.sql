@@ -0,0 +1,2 @@
+-- the removed rows belonged to deleted issues and are not restored
+SELECT 1;
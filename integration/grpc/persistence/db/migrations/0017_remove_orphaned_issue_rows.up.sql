@@ -0,0 +1,11 @@
+-- deleting an issue used to leave what belonged to it behind
+DELETE FROM issue_links WHERE link_fromId NOT IN (SELECT issue_id FROM issues) OR link_toId NOT IN (SELECT issue_id FROM issues);
+DELETE FROM issue_labels WHERE issue_id NOT IN (SELECT issue_id FROM issues);
+DELETE FROM issue_field_values WHERE value_issueId NOT IN (SELECT issue_id FROM issues);
+DELETE FROM issue_assignees WHERE issue_id NOT IN (SELECT issue_id FROM issues);
+DELETE FROM issue_watchers WHERE issue_id NOT IN (SELECT issue_id FROM issues);
+DELETE FROM comment_revisions WHERE revision_commentId IN (SELECT comment_id FROM comments WHERE comment_issueId NOT IN (SELECT issue_id FROM issues));
+DELETE FROM comments WHERE comment_issueId NOT IN (SELECT issue_id FROM issues);
+DELETE FROM issue_history WHERE history_issueId NOT IN (SELECT issue_id FROM issues);
+-- their contents are released by the tracker's startup sweep of the blob store
+DELETE FROM attachments WHERE attachment_issueId NOT IN (SELECT issue_id FROM issues);
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectNotification       = "SELECT * FROM notifications WHERE notification_id=?"
+	querySelectUserNotifications  = "SELECT * FROM notifications WHERE notification_userId=? ORDER BY notification_id DESC"
+	querySelectUnreadNotification = "SELECT * FROM notifications WHERE notification_userId=? AND notification_read=0 ORDER BY notification_id DESC"
+	queryInsertNotification       = "INSERT INTO notifications (notification_userId, notification_issueId, notification_event, notification_message, notification_createdAt) VALUES (?, ?, ?, ?, ?)"
+	queryMarkNotificationRead     = "UPDATE notifications SET notification_read=1 WHERE notification_id=?"
+	queryMarkNotificationsRead    = "UPDATE notifications SET notification_read=1 WHERE notification_userId=?"
+)
+
+type NotificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewNotificationRepository(db *sqlx.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db: db,
+	}
+}
+
+func (r *NotificationRepository) GetById(id int64) (*domain.Notification, error) {
+	var n domain.Notification
+	err := r.db.Get(&n, querySelectNotification, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// ForUser returns the newest notifications first.
+func (r *NotificationRepository) ForUser(userId int64, unreadOnly bool) ([]*domain.Notification, error) {
+	query := querySelectUserNotifications
+	if unreadOnly {
+		query = querySelectUnreadNotification
+	}
+	notifications := make([]*domain.Notification, 0)
+	if err := r.db.Select(&notifications, query, userId); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *NotificationRepository) Create(n *domain.Notification) error {
+	res, err := r.db.Exec(queryInsertNotification, n.UserId, n.IssueId, n.Event, n.Message, n.CreatedAt)
+	if err != nil {
+		return err
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	n.Id = lastId
+	return nil
+}
+
+func (r *NotificationRepository) MarkRead(id int64) error {
+	res, err := r.db.Exec(queryMarkNotificationRead, id)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func (r *NotificationRepository) MarkAllRead(userId int64) error {
+	_, err := r.db.Exec(queryMarkNotificationsRead, userId)
+	return err
+}
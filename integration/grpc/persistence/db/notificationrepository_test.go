@@ -0,0 +1,44 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+)
+
+func TestNotificationRepository_MarkRead(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewNotificationRepository(conn)
+
+	for _, message := range []string{"first", "second"} {
+		n := &domain.Notification{UserId: 1, IssueId: 1, Event: domain.EventIssueCommented, Message: message, CreatedAt: time.Now().UTC()}
+		if err := repo.Create(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	inbox, err := repo.ForUser(1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inbox) != 2 || inbox[0].Message != "second" {
+		t.Fatalf("expected the newest unread notification first but got %+v", inbox)
+	}
+
+	if err := repo.MarkRead(inbox[0].Id); err != nil {
+		t.Fatal(err)
+	}
+	if unread, _ := repo.ForUser(1, true); len(unread) != 1 || unread[0].Message != "first" {
+		t.Fatalf("expected only the first notification unread but got %+v", unread)
+	}
+	if all, _ := repo.ForUser(1, false); len(all) != 2 {
+		t.Fatalf("expected read notifications to stay in the inbox but got %+v", all)
+	}
+	if err := repo.MarkRead(42); err != domain.ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	querySelectAssignees = "SELECT user_id FROM issue_assignees WHERE issue_id=? ORDER BY user_id"
+	queryInsertAssignee  = "INSERT OR IGNORE INTO issue_assignees (issue_id, user_id) VALUES (?, ?)"
+	queryDeleteAssignee  = "DELETE FROM issue_assignees WHERE issue_id=? AND user_id=?"
+	querySelectWatchers  = "SELECT user_id FROM issue_watchers WHERE issue_id=? ORDER BY user_id"
+	queryInsertWatcher   = "INSERT OR IGNORE INTO issue_watchers (issue_id, user_id) VALUES (?, ?)"
+	queryDeleteWatcher   = "DELETE FROM issue_watchers WHERE issue_id=? AND user_id=?"
+)
+
+type ParticipantRepository struct {
+	db *sqlx.DB
+}
+
+func NewParticipantRepository(db *sqlx.DB) *ParticipantRepository {
+	return &ParticipantRepository{
+		db: db,
+	}
+}
+
+func (r *ParticipantRepository) Assignees(issueId int64) ([]int64, error) {
+	return r.users(querySelectAssignees, issueId)
+}
+
+func (r *ParticipantRepository) Watchers(issueId int64) ([]int64, error) {
+	return r.users(querySelectWatchers, issueId)
+}
+
+func (r *ParticipantRepository) users(query string, issueId int64) ([]int64, error) {
+	ids := make([]int64, 0)
+	if err := r.db.Select(&ids, query, issueId); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *ParticipantRepository) Assign(issueId, userId int64) error {
+	_, err := r.db.Exec(queryInsertAssignee, issueId, userId)
+	return err
+}
+
+func (r *ParticipantRepository) Unassign(issueId, userId int64) error {
+	res, err := r.db.Exec(queryDeleteAssignee, issueId, userId)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func (r *ParticipantRepository) Watch(issueId, userId int64) error {
+	_, err := r.db.Exec(queryInsertWatcher, issueId, userId)
+	return err
+}
+
+func (r *ParticipantRepository) Unwatch(issueId, userId int64) error {
+	res, err := r.db.Exec(queryDeleteWatcher, issueId, userId)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectAllProjects = "SELECT * FROM projects"
+	querySelectProject     = "SELECT * FROM projects WHERE project_id=?"
+	queryInsertProject     = "INSERT INTO projects (project_name, project_ownerId, project_description) VALUES (?, ?, ?)"
+	queryUpdateProject     = "UPDATE projects SET project_name=?, project_ownerId=?, project_description=? WHERE project_id=?"
+	queryDeleteProject     = "DELETE FROM projects WHERE project_id=? AND NOT EXISTS (SELECT 1 FROM issues WHERE issue_projectId=projects.project_id)"
+)
+
+type ProjectRepository struct {
+	db *sqlx.DB
+}
+
+func NewProjectRepository(db *sqlx.DB) *ProjectRepository {
+	return &ProjectRepository{
+		db: db,
+	}
+}
+
+func (r *ProjectRepository) All() ([]*domain.Project, error) {
+	projects := make([]*domain.Project, 0)
+	err := r.db.Select(&projects, querySelectAllProjects)
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (r *ProjectRepository) GetById(id int64) (*domain.Project, error) {
+	var p domain.Project
+	err := r.db.Get(&p, querySelectProject, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *ProjectRepository) Create(p *domain.Project) error {
+	res, err := r.db.Exec(queryInsertProject, p.Name, p.OwnerId, p.Description)
+	if err != nil {
+		return err
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	p.Id = lastId
+	return nil
+}
+
+func (r *ProjectRepository) Update(p *domain.Project) error {
+	res, err := r.db.Exec(queryUpdateProject, p.Name, p.OwnerId, p.Description, p.Id)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+// Delete checks for issues in the same statement, so an issue created
+// concurrently can't be left without its project.
+func (r *ProjectRepository) Delete(id int64) error {
+	res, err := r.db.Exec(queryDeleteProject, id)
+	if err != nil {
+		return err
+	}
+	if err := expectOneRow(res); err != domain.ErrNotFound {
+		return err
+	}
+	if _, err := r.GetById(id); err != nil {
+		return err
+	}
+	return fmt.Errorf("project %d still has issues: %w", id, domain.ErrConflict)
+}
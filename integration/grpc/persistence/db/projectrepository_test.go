@@ -0,0 +1,75 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pwera/ddd/domain"
+)
+
+func TestProjectRepository_CRUD(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewProjectRepository(conn)
+
+	p := &domain.Project{Name: "Tracker", OwnerId: 1, Description: "issues"}
+	if err := repo.Create(p); err != nil {
+		t.Fatal(err)
+	}
+	p.Name = "Renamed"
+	if err := repo.Update(p); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := repo.GetById(p.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *stored != *p {
+		t.Fatalf("expected %+v but got %+v", p, stored)
+	}
+
+	if err := repo.Update(&domain.Project{Id: 99, Name: "Missing"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound updating a missing project but got %v", err)
+	}
+	if err := repo.Delete(99); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound deleting a missing project but got %v", err)
+	}
+	if err := repo.Delete(p.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetById(p.Id); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected the project to be deleted but got %v", err)
+	}
+}
+
+func TestProjectRepository_DeleteWithIssues(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewProjectRepository(conn)
+	issues := NewIssueRepository(conn)
+
+	p := &domain.Project{Name: "Tracker"}
+	if err := repo.Create(p); err != nil {
+		t.Fatal(err)
+	}
+	issue := &domain.Issue{Title: "Bug", ProjectId: p.Id, Priority: domain.PriorityLow, Status: "open"}
+	if err := issues.Create(issue); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Delete(p.Id); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict deleting a project with issues but got %v", err)
+	}
+	if err := issues.Delete(issue.Id); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(p.Id); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectSavedQuery  = "SELECT * FROM saved_queries WHERE query_id=?"
+	querySelectUserQueries = "SELECT * FROM saved_queries WHERE query_userId=? ORDER BY query_name"
+	queryInsertSavedQuery  = "INSERT INTO saved_queries (query_userId, query_name, query_text) VALUES (?, ?, ?)"
+	queryDeleteSavedQuery  = "DELETE FROM saved_queries WHERE query_id=?"
+)
+
+type SavedQueryRepository struct {
+	db *sqlx.DB
+}
+
+func NewSavedQueryRepository(db *sqlx.DB) *SavedQueryRepository {
+	return &SavedQueryRepository{
+		db: db,
+	}
+}
+
+func (r *SavedQueryRepository) GetById(id int64) (*domain.SavedQuery, error) {
+	var q domain.SavedQuery
+	err := r.db.Get(&q, querySelectSavedQuery, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (r *SavedQueryRepository) ForUser(userId int64) ([]*domain.SavedQuery, error) {
+	queries := make([]*domain.SavedQuery, 0)
+	if err := r.db.Select(&queries, querySelectUserQueries, userId); err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
+
+func (r *SavedQueryRepository) Create(q *domain.SavedQuery) error {
+	res, err := r.db.Exec(queryInsertSavedQuery, q.UserId, q.Name, q.Query)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("query %s already exists: %w", q.Name, domain.ErrConflict)
+	}
+	if err != nil {
+		return err
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	q.Id = lastId
+	return nil
+}
+
+func (r *SavedQueryRepository) Delete(id int64) error {
+	res, err := r.db.Exec(queryDeleteSavedQuery, id)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectSprint         = "SELECT * FROM sprints WHERE sprint_id=?"
+	querySelectProjectSprints = "SELECT * FROM sprints WHERE sprint_projectId=? ORDER BY sprint_start, sprint_id"
+	queryInsertSprint         = "INSERT INTO sprints (sprint_projectId, sprint_name, sprint_kind, sprint_start, sprint_end) VALUES (?, ?, ?, ?, ?)"
+	queryCountSprintIssues    = "SELECT COUNT(*) FROM issues WHERE issue_sprintId=?"
+	queryDeleteSprint         = "DELETE FROM sprints WHERE sprint_id=?"
+)
+
+type SprintRepository struct {
+	db *sqlx.DB
+}
+
+func NewSprintRepository(db *sqlx.DB) *SprintRepository {
+	return &SprintRepository{
+		db: db,
+	}
+}
+
+func (r *SprintRepository) GetById(id int64) (*domain.Sprint, error) {
+	var s domain.Sprint
+	err := r.db.Get(&s, querySelectSprint, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *SprintRepository) ForProject(projectId int64) ([]*domain.Sprint, error) {
+	sprints := make([]*domain.Sprint, 0)
+	if err := r.db.Select(&sprints, querySelectProjectSprints, projectId); err != nil {
+		return nil, err
+	}
+	return sprints, nil
+}
+
+func (r *SprintRepository) Create(s *domain.Sprint) error {
+	res, err := r.db.Exec(queryInsertSprint, s.ProjectId, s.Name, s.Kind, s.Start, s.End)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("sprint %s already exists: %w", s.Name, domain.ErrConflict)
+	}
+	if err != nil {
+		return err
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	s.Id = lastId
+	return nil
+}
+
+func (r *SprintRepository) Delete(id int64) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var issues int
+	if err := tx.Get(&issues, queryCountSprintIssues, id); err != nil {
+		return err
+	}
+	if issues > 0 {
+		return fmt.Errorf("sprint %d still has %d issues: %w", id, issues, domain.ErrConflict)
+	}
+	res, err := tx.Exec(queryDeleteSprint, id)
+	if err != nil {
+		return err
+	}
+	if err := expectOneRow(res); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
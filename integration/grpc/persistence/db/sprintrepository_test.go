@@ -0,0 +1,60 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+)
+
+func TestSprintRepository_CRUD(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewSprintRepository(conn)
+
+	start := domain.Day(time.Now().UTC())
+	second := &domain.Sprint{ProjectId: 1, Name: "Second", Kind: domain.SprintKindSprint, Start: start.AddDate(0, 0, 14), End: start.AddDate(0, 0, 27)}
+	first := &domain.Sprint{ProjectId: 1, Name: "First", Kind: domain.SprintKindMilestone, Start: start, End: start.AddDate(0, 0, 13)}
+	for _, s := range []*domain.Sprint{second, first} {
+		if err := repo.Create(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Create(&domain.Sprint{ProjectId: 1, Name: "First", Start: start, End: start}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict for a second sprint named First but got %v", err)
+	}
+
+	sprints, err := repo.ForProject(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sprints) != 2 || sprints[0].Id != first.Id || sprints[1].Id != second.Id {
+		t.Fatalf("expected the sprints in the order they start but got %+v", sprints)
+	}
+	stored, err := repo.GetById(first.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Kind != domain.SprintKindMilestone || !stored.Start.Equal(start) {
+		t.Fatalf("expected %+v but got %+v", first, stored)
+	}
+
+	now := time.Now().UTC()
+	issue := &domain.Issue{Title: "Bug", Priority: domain.PriorityLow, Status: "Open", SprintId: first.Id, CreatedAt: now, UpdatedAt: now}
+	if err := NewIssueRepository(conn).Create(issue); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(first.Id); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict deleting a sprint with issues but got %v", err)
+	}
+	if err := repo.Delete(second.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetById(second.Id); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for the deleted sprint but got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectRefreshToken  = "SELECT * FROM refresh_tokens WHERE refresh_hash=?"
+	queryInsertRefreshToken  = "INSERT INTO refresh_tokens (refresh_hash, refresh_userId, refresh_family, refresh_expiresAt) VALUES (?, ?, ?, ?)"
+	queryUseRefreshToken     = "UPDATE refresh_tokens SET refresh_usedAt=? WHERE refresh_hash=? AND refresh_usedAt IS NULL"
+	queryRevokeTokenFamily   = "UPDATE refresh_tokens SET refresh_revoked=1 WHERE refresh_family=?"
+	queryInsertRevokedToken  = "INSERT OR IGNORE INTO revoked_tokens (revoked_id, revoked_expiresAt) VALUES (?, ?)"
+	querySelectRevokedTokens = "SELECT * FROM revoked_tokens WHERE revoked_expiresAt>? ORDER BY revoked_expiresAt"
+	queryCountRevokedToken   = "SELECT COUNT(*) FROM revoked_tokens WHERE revoked_id=? AND revoked_expiresAt>?"
+	queryDeleteExpiredTokens = "DELETE FROM revoked_tokens WHERE revoked_expiresAt<=?"
+)
+
+// TokenRepository keeps the refresh tokens and the revoked access tokens.
+type TokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewTokenRepository(db *sqlx.DB) *TokenRepository {
+	return &TokenRepository{
+		db: db,
+	}
+}
+
+func (r *TokenRepository) CreateRefreshToken(t *domain.RefreshToken) error {
+	_, err := r.db.Exec(queryInsertRefreshToken, t.Hash, t.UserId, t.Family, t.ExpiresAt)
+	return err
+}
+
+func (r *TokenRepository) RefreshToken(hash string) (*domain.RefreshToken, error) {
+	var t domain.RefreshToken
+	err := r.db.Get(&t, querySelectRefreshToken, hash)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// UseRefreshToken only marks unused tokens, so of two concurrent uses only one wins.
+func (r *TokenRepository) UseRefreshToken(hash string, at time.Time) (bool, error) {
+	res, err := r.db.Exec(queryUseRefreshToken, at, hash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *TokenRepository) RevokeFamily(family string) error {
+	_, err := r.db.Exec(queryRevokeTokenFamily, family)
+	return err
+}
+
+func (r *TokenRepository) Revoke(t *domain.RevokedToken) error {
+	_, err := r.db.Exec(queryInsertRevokedToken, t.Id, t.ExpiresAt)
+	return err
+}
+
+func (r *TokenRepository) IsRevoked(id string, now time.Time) (bool, error) {
+	var n int
+	if err := r.db.Get(&n, queryCountRevokedToken, id, now); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *TokenRepository) Revoked(now time.Time) ([]*domain.RevokedToken, error) {
+	tokens := make([]*domain.RevokedToken, 0)
+	if err := r.db.Select(&tokens, querySelectRevokedTokens, now); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *TokenRepository) PruneRevoked(now time.Time) error {
+	_, err := r.db.Exec(queryDeleteExpiredTokens, now)
+	return err
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pwera/ddd/domain"
+)
+
+func TestTokenRepository_UseRefreshTokenOnce(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewTokenRepository(conn)
+
+	now := time.Now().UTC()
+	token := &domain.RefreshToken{Hash: "h1", UserId: 1, Family: "f", ExpiresAt: now.Add(time.Hour)}
+	if err := repo.CreateRefreshToken(token); err != nil {
+		t.Fatal(err)
+	}
+	if used, err := repo.UseRefreshToken("h1", now); err != nil || !used {
+		t.Fatalf("expected the first use to succeed but got %v, %v", used, err)
+	}
+	if used, _ := repo.UseRefreshToken("h1", now); used {
+		t.Fatal("expected the second use to be refused")
+	}
+	if err := repo.RevokeFamily("f"); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := repo.RefreshToken("h1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.Revoked || stored.UsedAt == nil {
+		t.Fatalf("expected a used and revoked token but got %+v", stored)
+	}
+}
+
+func TestTokenRepository_Revoked(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewTokenRepository(conn)
+
+	now := time.Now().UTC()
+	for id, exp := range map[string]time.Time{"expired": now.Add(-time.Minute), "live": now.Add(time.Minute)} {
+		if err := repo.Revoke(&domain.RevokedToken{Id: id, ExpiresAt: exp}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	revoked, err := repo.Revoked(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revoked) != 1 || revoked[0].Id != "live" {
+		t.Fatalf("expected only the live token but got %+v", revoked)
+	}
+	for id, want := range map[string]bool{"live": true, "expired": false, "unknown": false} {
+		if got, err := repo.IsRevoked(id, now); err != nil || got != want {
+			t.Errorf("expected %s to be revoked %v but got %v, %v", id, want, got, err)
+		}
+	}
+
+	if err := repo.PruneRevoked(now); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := conn.Get(&n, "SELECT COUNT(*) FROM revoked_tokens"); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected only the live token to be kept but got %d tokens", n)
+	}
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectAllUsers   = "SELECT * FROM users ORDER BY user_id"
+	querySelectUser       = "SELECT * FROM users WHERE user_id=?"
+	querySelectUserByMail = "SELECT * FROM users WHERE user_email=?"
+	queryInsertUser       = "INSERT INTO users (user_name, user_email, user_passwordHash) VALUES (?, ?, ?)"
+	queryUpdateUser       = "UPDATE users SET user_name=?, user_email=? WHERE user_id=?"
+	queryDeleteUser       = "DELETE FROM users WHERE user_id=?"
+)
+
+// UserRepository is the SQLite implementation of domain.UserRepository.
+type UserRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserRepository(db *sqlx.DB) *UserRepository {
+	return &UserRepository{
+		db: db,
+	}
+}
+
+func (r *UserRepository) All() ([]*domain.User, error) {
+	users := make([]*domain.User, 0)
+	err := r.db.Select(&users, querySelectAllUsers)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *UserRepository) User(id int64) (*domain.User, error) {
+	return r.get(querySelectUser, id)
+}
+
+func (r *UserRepository) ByEmail(email string) (*domain.User, error) {
+	return r.get(querySelectUserByMail, email)
+}
+
+func (r *UserRepository) get(query string, arg interface{}) (*domain.User, error) {
+	var u domain.User
+	err := r.db.Get(&u, query, arg)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *UserRepository) Create(u *domain.User) error {
+	res, err := r.db.Exec(queryInsertUser, u.Name, u.Email, u.PasswordHash)
+	if err != nil {
+		return userError(u, err)
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	u.Id = lastId
+	return nil
+}
+
+func (r *UserRepository) Update(u *domain.User) error {
+	res, err := r.db.Exec(queryUpdateUser, u.Name, u.Email, u.Id)
+	if err != nil {
+		return userError(u, err)
+	}
+	return expectOneRow(res)
+}
+
+func (r *UserRepository) Delete(id int64) error {
+	res, err := r.db.Exec(queryDeleteUser, id)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+// userError turns the violation of the unique email index into domain.ErrConflict.
+func userError(u *domain.User, err error) error {
+	if isUniqueViolation(err) {
+		return fmt.Errorf("email %s is already taken: %w", u.Email, domain.ErrConflict)
+	}
+	return err
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pwera/ddd/domain"
+)
+
+func TestUserRepository_UniqueEmail(t *testing.T) {
+	conn, err := Open(DefaultDSN)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer conn.Close()
+	repo := NewUserRepository(conn)
+
+	ann := &domain.User{Name: "Ann", Email: "ann@example.com"}
+	bob := &domain.User{Name: "Bob", Email: "bob@example.com"}
+	for _, u := range []*domain.User{ann, bob} {
+		if err := repo.Create(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.Create(&domain.User{Name: "Other", Email: "ann@example.com"}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict creating a user with a taken email but got %v", err)
+	}
+	bob.Email = ann.Email
+	if err := repo.Update(bob); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict taking another user's email but got %v", err)
+	}
+	stored, err := repo.User(bob.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Email != "bob@example.com" {
+		t.Fatalf("expected the email to be left unchanged but got %q", stored.Email)
+	}
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+)
+
+const (
+	querySelectWorkflow = "SELECT workflow_definition FROM workflows WHERE workflow_projectId=?"
+	queryUpsertWorkflow = "INSERT INTO workflows (workflow_projectId, workflow_definition) VALUES (?, ?) ON CONFLICT(workflow_projectId) DO UPDATE SET workflow_definition=excluded.workflow_definition"
+)
+
+// WorkflowRepository stores a JSON document per project.
+type WorkflowRepository struct {
+	db *sqlx.DB
+}
+
+func NewWorkflowRepository(db *sqlx.DB) *WorkflowRepository {
+	return &WorkflowRepository{
+		db: db,
+	}
+}
+
+func (r *WorkflowRepository) Workflow(projectId int64) (*domain.Workflow, error) {
+	var definition string
+	err := r.db.Get(&definition, querySelectWorkflow, projectId)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var w domain.Workflow
+	if err := json.Unmarshal([]byte(definition), &w); err != nil {
+		return nil, err
+	}
+	w.ProjectId = projectId
+	return &w, nil
+}
+
+func (r *WorkflowRepository) Save(w *domain.Workflow) error {
+	definition, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(queryUpsertWorkflow, w.ProjectId, string(definition))
+	return err
+}
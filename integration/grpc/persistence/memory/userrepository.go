@@ -1,82 +1,130 @@
 package memory
 
 import (
-	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
-	"github.com/patrickmn/go-cache"
 	"github.com/pwera/ddd/domain"
 )
 
-const (
-	UsersAllKey = "Users:all"
-	UserLastId  = "User:lastId"
-)
-
-var (
-	ErrorEmpty    = errors.New("Empty")
-	ErrorNotFound = errors.New("Not Found")
-)
+var ErrorNotFound = fmt.Errorf("user %w", domain.ErrNotFound)
 
+// UserRepository keeps the users in memory, indexed by id and by email.
+// It hands out copies so callers can't change the stored users.
 type UserRepository struct {
-	db *cache.Cache
+	mu      sync.RWMutex
+	lastId  int64
+	users   map[int64]domain.User
+	byEmail map[string]int64
 }
 
 func NewUserRepository() *UserRepository {
-	db := cache.New(cache.NoExpiration, cache.NoExpiration)
-	db.SetDefault(UserLastId, int64(0))
-	db.SetDefault(UsersAllKey, []*domain.User{})
 	return &UserRepository{
-		db: db,
+		users:   map[int64]domain.User{},
+		byEmail: map[string]int64{},
 	}
 }
 
 func (r *UserRepository) All() ([]*domain.User, error) {
-	result, ok := r.db.Get(UsersAllKey)
-	if ok {
-		return result.([]*domain.User), nil
-	} else {
-		return nil, ErrorEmpty
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*domain.User, 0, len(r.users))
+	for _, u := range r.users {
+		u := u
+		users = append(users, &u)
 	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Id < users[j].Id })
+	return users, nil
 }
-func (r *UserRepository) getById(id int64) (*domain.User, error) {
-	result, ok := r.db.Get(UsersAllKey)
-	if ok {
-		items := result.([]*domain.User)
-		for _, user := range items {
-			if user.Id == id {
-				return user, nil
-			}
-		}
-		return nil, ErrorEmpty
+
+func (r *UserRepository) Create(u *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkEmail(u.Email, 0); err != nil {
+		return err
 	}
-	return nil, ErrorEmpty
+	r.lastId++
+	u.Id = r.lastId
+	r.store(*u)
+	return nil
 }
-func (r *UserRepository) Create(u *domain.User) error {
-	id, _ := r.db.IncrementInt64(UserLastId, int64(1))
-	u.Id = id
 
-	result, ok := r.db.Get(UsersAllKey)
-	if ok {
-		result = append(result.([]*domain.User), u)
-		r.db.Set(UsersAllKey, result, cache.NoExpiration)
+func (r *UserRepository) Update(u *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.users[u.Id]
+	if !ok {
+		return ErrorNotFound
+	}
+	if err := r.checkEmail(u.Email, u.Id); err != nil {
+		return err
 	}
+	delete(r.byEmail, normalizeEmail(old.Email))
+	updated := *u
+	updated.PasswordHash = old.PasswordHash
+	r.store(updated)
 	return nil
 }
+
 func (r *UserRepository) Delete(id int64) error {
-	result, ok := r.db.Get(UsersAllKey)
-	if ok {
-		items := result.([]*domain.User)
-		for i, user := range items {
-			if user.Id == id {
-				items = append(items[:i], items[i+1:]...)
-				return nil
-			}
-		}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[id]
+	if !ok {
 		return ErrorNotFound
 	}
-	return ErrorNotFound
+	delete(r.users, id)
+	delete(r.byEmail, normalizeEmail(u.Email))
+	return nil
 }
 
 func (r *UserRepository) User(id int64) (*domain.User, error) {
-	return nil, nil
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return nil, ErrorNotFound
+	}
+	return &u, nil
+}
+
+func (r *UserRepository) ByEmail(email string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byEmail[normalizeEmail(email)]
+	if !ok {
+		return nil, ErrorNotFound
+	}
+	u := r.users[id]
+	return &u, nil
+}
+
+// checkEmail fails when email already belongs to a user other than id.
+func (r *UserRepository) checkEmail(email string, id int64) error {
+	if email == "" {
+		return nil
+	}
+	if owner, ok := r.byEmail[normalizeEmail(email)]; ok && owner != id {
+		return fmt.Errorf("email %s is already taken: %w", email, domain.ErrConflict)
+	}
+	return nil
+}
+
+func (r *UserRepository) store(u domain.User) {
+	r.users[u.Id] = u
+	if u.Email != "" {
+		r.byEmail[normalizeEmail(u.Email)] = u.Id
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/pwera/ddd/domain"
+)
+
+func TestUserRepository_CRUD(t *testing.T) {
+	repo := NewUserRepository()
+	u := &domain.User{Name: "Ann", Email: "ann@example.com", PasswordHash: "hash"}
+	if err := repo.Create(u); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := repo.User(u.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored.Name = "Changed"
+	if again, _ := repo.User(u.Id); again.Name != "Ann" {
+		t.Fatalf("expected a copy of the stored user but the change leaked: %+v", again)
+	}
+
+	if err := repo.Create(&domain.User{Name: "Other", Email: " ANN@example.com"}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict for a taken email but got %v", err)
+	}
+
+	if err := repo.Update(&domain.User{Id: u.Id, Name: "Ann", Email: "anne@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ByEmail("ann@example.com"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected the old email to be released but got %v", err)
+	}
+	updated, err := repo.ByEmail("Anne@Example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.PasswordHash != "hash" {
+		t.Fatalf("expected Update to keep the password hash but got %q", updated.PasswordHash)
+	}
+
+	if err := repo.Delete(u.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.User(u.Id); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after Delete but got %v", err)
+	}
+	if err := repo.Delete(u.Id); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound deleting twice but got %v", err)
+	}
+}
+
+// TestUserRepository_Concurrent is meant to run with -race.
+func TestUserRepository_Concurrent(t *testing.T) {
+	repo := NewUserRepository()
+	var wg sync.WaitGroup
+	for n := 0; n < 20; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			u := &domain.User{Email: fmt.Sprintf("user%d@example.com", n)}
+			if err := repo.Create(u); err != nil {
+				t.Error(err)
+				return
+			}
+			u.Name = "Renamed"
+			if err := repo.Update(u); err != nil {
+				t.Error(err)
+			}
+			if _, err := repo.All(); err != nil {
+				t.Error(err)
+			}
+			// every goroutine races for the same email, only one gets it
+			repo.Create(&domain.User{Email: "shared@example.com"})
+		}(n)
+	}
+	wg.Wait()
+
+	users, err := repo.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 21 {
+		t.Fatalf("expected 21 users but got %d", len(users))
+	}
+	for i, u := range users {
+		if u.Id != int64(i+1) {
+			t.Fatalf("expected unique sequential ids but got %d at %d", u.Id, i)
+		}
+	}
+}
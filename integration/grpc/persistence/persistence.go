@@ -0,0 +1,26 @@
+package persistence
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/domain"
+	"github.com/pwera/ddd/persistence/db"
+	"github.com/pwera/ddd/persistence/memory"
+)
+
+const (
+	UserStoreMemory = "memory"
+	UserStoreSQLite = "sqlite"
+)
+
+// NewUserRepository returns the UserRepository selected with the -user-store flag.
+func NewUserRepository(store string, conn *sqlx.DB) (domain.UserRepository, error) {
+	switch store {
+	case UserStoreMemory:
+		return memory.NewUserRepository(), nil
+	case UserStoreSQLite:
+		return db.NewUserRepository(conn), nil
+	}
+	return nil, fmt.Errorf("unknown user store %q, use %s or %s", store, UserStoreMemory, UserStoreSQLite)
+}
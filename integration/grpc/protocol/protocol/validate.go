@@ -0,0 +1,139 @@
+package protocol
+
+import "errors"
+
+// The Validate methods check what the messages need to be served at all, the
+// services still validate the values themselves.
+
+func (r *NewUserRequest) Validate() error {
+	if r.Email == "" || r.Password == "" {
+		return errors.New("email and password are required")
+	}
+	return nil
+}
+
+func (r *LoginRequest) Validate() error {
+	if r.Email == "" || r.Password == "" {
+		return errors.New("email and password are required")
+	}
+	return nil
+}
+
+func (r *RefreshTokenRequest) Validate() error {
+	if r.RefreshToken == "" {
+		return errors.New("refresh_token is required")
+	}
+	return nil
+}
+
+func (r *LogoutRequest) Validate() error {
+	if r.AccessToken == "" && r.RefreshToken == "" {
+		return errors.New("access_token or refresh_token is required")
+	}
+	return nil
+}
+
+func (r *GetUserRequest) Validate() error {
+	return validId(r.Id)
+}
+
+func (r *ListUsersRequest) Validate() error {
+	return validPageSize(r.PageSize)
+}
+
+func (r *CreateUserRequest) Validate() error {
+	if r.User == nil {
+		return errors.New("user is required")
+	}
+	return nil
+}
+
+func (r *UpdateUserRequest) Validate() error {
+	if r.User == nil {
+		return errors.New("user is required")
+	}
+	return validId(r.User.Id)
+}
+
+func (r *DeleteUserRequest) Validate() error {
+	return validId(r.Id)
+}
+
+func (r *GetProjectRequest) Validate() error {
+	return validId(r.Id)
+}
+
+func (r *ListProjectsRequest) Validate() error {
+	return validPageSize(r.PageSize)
+}
+
+func (r *CreateProjectRequest) Validate() error {
+	if r.Project == nil {
+		return errors.New("project is required")
+	}
+	return nil
+}
+
+func (r *UpdateProjectRequest) Validate() error {
+	if r.Project == nil {
+		return errors.New("project is required")
+	}
+	return validId(r.Project.Id)
+}
+
+func (r *DeleteProjectRequest) Validate() error {
+	return validId(r.Id)
+}
+
+func (r *GetIssueRequest) Validate() error {
+	return validId(r.Id)
+}
+
+func (r *ListIssuesRequest) Validate() error {
+	return validPageSize(r.PageSize)
+}
+
+func (r *CreateIssueRequest) Validate() error {
+	if r.Issue == nil {
+		return errors.New("issue is required")
+	}
+	if _, ok := Priority_name[int32(r.Issue.Priority)]; !ok {
+		return errors.New("unknown priority")
+	}
+	return nil
+}
+
+func (r *UpdateIssueRequest) Validate() error {
+	if r.Issue == nil {
+		return errors.New("issue is required")
+	}
+	if _, ok := Priority_name[int32(r.Issue.Priority)]; !ok {
+		return errors.New("unknown priority")
+	}
+	return validId(r.Issue.Id)
+}
+
+func (r *DeleteIssueRequest) Validate() error {
+	return validId(r.Id)
+}
+
+func (r *WatchIssuesRequest) Validate() error {
+	if r.AfterEventId < 0 {
+		return errors.New("after_event_id can't be negative")
+	}
+	return nil
+}
+
+func validId(id int64) error {
+	if id <= 0 {
+		return errors.New("id must be positive")
+	}
+	return nil
+}
+
+func validPageSize(size int32) error {
+	if size < 0 {
+		return errors.New("page_size can't be negative")
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Package readiness keeps the grpc.health.v1 serving status of the services of a
+// server in step with the readiness of the repositories they depend on.
+package readiness
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Check reports whether a dependency of a service is ready, like the PingContext
+// of a database.
+type Check func(ctx context.Context) error
+
+// Remote checks a service of another server with its health service.
+func Remote(conn *grpc.ClientConn, service string) Check {
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return err
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("%s is %s", service, resp.Status)
+		}
+		return nil
+	}
+}
+
+// Checker sets the status of every service of Services from its checks, and
+// the status of the whole server, the empty service name, from all of them.
+// The statuses stop changing once the Server is shut down.
+type Checker struct {
+	Server   *health.Server
+	Services map[string][]Check
+	// Timeout bounds every check.
+	Timeout time.Duration
+}
+
+// Update runs the checks once and logs the services changing status.
+func (c Checker) Update(ctx context.Context) {
+	serving := true
+	for service, checks := range c.Services {
+		err := c.check(ctx, checks)
+		if err != nil {
+			serving = false
+		}
+		c.set(ctx, service, err)
+	}
+	if serving {
+		c.set(ctx, "", nil)
+	} else {
+		c.set(ctx, "", errNotServing)
+	}
+}
+
+// Run updates the statuses every interval until ctx is done.
+func (c Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.Update(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Handler serves the status of the service query parameter, the whole server
+// without one, answering 503 unless it is serving.
+func (c Checker) Handler(w http.ResponseWriter, r *http.Request) {
+	resp, err := c.Server.Check(r.Context(), &healthpb.HealthCheckRequest{Service: r.URL.Query().Get("service")})
+	switch {
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case resp.Status != healthpb.HealthCheckResponse_SERVING:
+		http.Error(w, resp.Status.String(), http.StatusServiceUnavailable)
+	default:
+		fmt.Fprintln(w, resp.Status)
+	}
+}
+
+var errNotServing = errors.New("a service is not serving")
+
+func (c Checker) check(ctx context.Context, checks []Check) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+	for _, check := range checks {
+		if err := check(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c Checker) set(ctx context.Context, service string, err error) {
+	status := healthpb.HealthCheckResponse_SERVING
+	if err != nil {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	if resp, checkErr := c.Server.Check(ctx, &healthpb.HealthCheckRequest{Service: service}); checkErr != nil || resp.Status != status {
+		if err != nil {
+			log.Printf("health: %q is %s: %v", service, status, err)
+		} else {
+			log.Printf("health: %q is %s", service, status)
+		}
+	}
+	c.Server.SetServingStatus(service, status)
+}
@@ -0,0 +1,103 @@
+package readiness
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestChecker_Update(t *testing.T) {
+	var dbErr error
+	server := health.NewServer()
+	checker := Checker{
+		Server: server,
+		Services: map[string][]Check{
+			"api":     {func(ctx context.Context) error { return nil }},
+			"gateway": {func(ctx context.Context) error { return dbErr }},
+		},
+		Timeout: time.Second,
+	}
+	ctx := context.Background()
+	expect := func(service string, want healthpb.HealthCheckResponse_ServingStatus, code int) {
+		t.Helper()
+		resp, err := server.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Status != want {
+			t.Fatalf("expected %q to be %s but got %s", service, want, resp.Status)
+		}
+		w := httptest.NewRecorder()
+		checker.Handler(w, httptest.NewRequest(http.MethodGet, "/health?service="+service, nil))
+		if w.Code != code {
+			t.Fatalf("expected %d for %q but got %d", code, service, w.Code)
+		}
+	}
+
+	checker.Update(ctx)
+	expect("", healthpb.HealthCheckResponse_SERVING, http.StatusOK)
+	expect("gateway", healthpb.HealthCheckResponse_SERVING, http.StatusOK)
+
+	dbErr = errors.New("database is locked")
+	checker.Update(ctx)
+	expect("", healthpb.HealthCheckResponse_NOT_SERVING, http.StatusServiceUnavailable)
+	expect("api", healthpb.HealthCheckResponse_SERVING, http.StatusOK)
+	expect("gateway", healthpb.HealthCheckResponse_NOT_SERVING, http.StatusServiceUnavailable)
+
+	dbErr = nil
+	server.Shutdown()
+	checker.Update(ctx)
+	expect("api", healthpb.HealthCheckResponse_NOT_SERVING, http.StatusServiceUnavailable)
+
+	w := httptest.NewRecorder()
+	checker.Handler(w, httptest.NewRequest(http.MethodGet, "/health?service=unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown service but got %d", w.Code)
+	}
+}
+
+func TestRemote(t *testing.T) {
+	remote := health.NewServer()
+	remote.SetServingStatus("tracker", healthpb.HealthCheckResponse_SERVING)
+	remote.SetServingStatus("draining", healthpb.HealthCheckResponse_NOT_SERVING)
+	lis := bufconn.Listen(1 << 16)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, remote)
+	go server.Serve(lis)
+	defer server.Stop()
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := Remote(conn, "tracker")(ctx); err != nil {
+		t.Fatalf("expected a serving service to be ready but got %v", err)
+	}
+	if err := Remote(conn, "draining")(ctx); err == nil {
+		t.Fatal("expected a service not serving to fail the check")
+	}
+	if err := Remote(conn, "unknown")(ctx); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected an unknown service to fail the check but got %v", err)
+	}
+	remote.Shutdown()
+	if err := Remote(conn, "tracker")(ctx); err == nil {
+		t.Fatal("expected a shut down server to fail the check")
+	}
+}
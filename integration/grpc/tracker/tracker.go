@@ -0,0 +1,65 @@
+// Package tracker wires the application services of the issue tracker to the
+// SQLite repositories. The tracker and the auth server both serve issues, so
+// they share it to record the same events and notify the same users.
+package tracker
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/pwera/ddd/application"
+	"github.com/pwera/ddd/domain"
+	"github.com/pwera/ddd/persistence/db"
+)
+
+// Services are the services every change of an issue goes through.
+type Services struct {
+	Users         application.UserService
+	Projects      application.ProjectService
+	Issues        application.IssueService
+	Activity      application.ActivityService
+	Notifications application.NotificationService
+	Events        *application.EventBus
+}
+
+// NewServices records the published events before notifying the users about them.
+func NewServices(conn *sqlx.DB, userRepo domain.UserRepository, blobStore domain.BlobStore, sla domain.SLA) Services {
+	issueRepo := db.NewIssueRepository(conn)
+	projectRepo := db.NewProjectRepository(conn)
+	workflowRepo := db.NewWorkflowRepository(conn)
+	participantRepo := db.NewParticipantRepository(conn)
+	notificationService := application.NotificationService{
+		NotificationRepository: db.NewNotificationRepository(conn),
+		ParticipantRepository:  participantRepo,
+		UserRepository:         userRepo,
+	}
+	activityService := application.ActivityService{
+		EventRepository: db.NewEventRepository(conn),
+		IssueRepository: issueRepo,
+	}
+	events := &application.EventBus{}
+	events.Subscribe(activityService.Record)
+	events.Subscribe(notificationService.Handle)
+	return Services{
+		Users: application.UserService{UserRepository: userRepo},
+		Projects: application.ProjectService{
+			ProjectRepository:  projectRepo,
+			WorkflowRepository: workflowRepo,
+		},
+		Issues: application.IssueService{
+			IssueRepository:       issueRepo,
+			ProjectRepository:     projectRepo,
+			WorkflowRepository:    workflowRepo,
+			HistoryRepository:     db.NewHistoryRepository(conn),
+			LabelRepository:       db.NewLabelRepository(conn),
+			FieldRepository:       db.NewFieldRepository(conn),
+			ParticipantRepository: participantRepo,
+			SprintRepository:      db.NewSprintRepository(conn),
+			AttachmentRepository:  db.NewAttachmentRepository(conn),
+			BlobStore:             blobStore,
+			Events:                events,
+			SLA:                   sla,
+		},
+		Activity:      activityService,
+		Notifications: notificationService,
+		Events:        events,
+	}
+}